@@ -0,0 +1,134 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type adminStatus struct {
+	Level   Level        `json:"level"`
+	Writers []WriterInfo `json:"writers"`
+}
+
+type adminRequest struct {
+	Logger string `json:"logger"`
+	Writer string `json:"writer"`
+	Level  *Level `json:"level"`
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+type adminHandler struct {
+	logger Logger
+}
+
+// AdminHandler 返回用于查看和调整日志级别的 http.Handler：
+//
+//	GET               返回全局级别及各 Writer 的级别、Filter 和计数
+//	PUT/POST          {"level": "debug"} 调整全局级别
+//	                  {"writer": "file", "level": "debug"} 调整指定 Writer 的级别
+//	                  {"logger": "app/db", "level": "warning"} 调整指定命名 Logger 的级别
+//
+// 也可以使用 query 参数 logger、writer 和 level 代替请求体。
+func AdminHandler(l Logger) http.Handler {
+	if l == nil {
+		l = sharedLogger
+	}
+	return &adminHandler{logger: l}
+}
+
+func (this *adminHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		if status, err := this.update(req); err != nil {
+			this.writeJSON(w, status, adminError{Error: err.Error()})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		this.writeJSON(w, http.StatusMethodNotAllowed, adminError{Error: "不支持的请求方法 " + req.Method})
+		return
+	}
+
+	this.writeJSON(w, http.StatusOK, adminStatus{
+		Level:   this.logger.Level(),
+		Writers: this.logger.WriterInfos(),
+	})
+}
+
+// update 根据请求调整级别，出错时返回对应的状态码
+func (this *adminHandler) update(req *http.Request) (int, error) {
+	var param adminRequest
+
+	var query = req.URL.Query()
+	if query.Get("level") != "" {
+		var level Level
+		if err := level.UnmarshalText([]byte(query.Get("level"))); err != nil {
+			return http.StatusBadRequest, err
+		}
+		param.Logger = query.Get("logger")
+		param.Writer = query.Get("writer")
+		param.Level = &level
+	} else if req.Body != nil {
+		var decoder = json.NewDecoder(req.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&param); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("解析请求体出错: %v", err)
+		}
+	}
+
+	if param.Level == nil {
+		return http.StatusBadRequest, fmt.Errorf("缺少 level 参数")
+	}
+
+	var target = this.logger
+	if strings.TrimSpace(param.Logger) != "" {
+		// 只查找已经存在的 Logger，避免通过请求创建任意数量的 Logger
+		if l, ok := this.logger.(*logger); ok {
+			c, found := l.lookup(param.Logger)
+			if !found {
+				return http.StatusNotFound, fmt.Errorf("Logger %s 不存在", param.Logger)
+			}
+			target = c
+		} else {
+			target = this.logger.GetLogger(param.Logger)
+		}
+	}
+
+	var name = strings.TrimSpace(param.Writer)
+	if name == "" {
+		target.SetLevel(*param.Level)
+		return 0, nil
+	}
+
+	var writer = target.Writer(name)
+	if writer == nil {
+		return http.StatusNotFound, fmt.Errorf("Writer %s 不存在", name)
+	}
+	ls, ok := writer.(LevelSetter)
+	if !ok {
+		return http.StatusBadRequest, fmt.Errorf("Writer %s 不支持调整级别", name)
+	}
+	ls.SetLevel(*param.Level)
+	return 0, nil
+}
+
+// writeJSON 先编码到缓冲区中，编码失败时返回 500，避免已经写入状态码之后才发现出错
+func (this *adminHandler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	var encoder = json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf("编码响应出错: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,108 @@
+package log4go_test
+
+import (
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w, log4go.LevelRange(log4go.LevelTrace, log4go.LevelInfo))
+
+	var server = httptest.NewServer(log4go.AdminHandler(l))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{"writer":"memory","level":"debug"}`))
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK || w.Level() != log4go.LevelDebug {
+		t.Fatalf("调整 Writer 级别失败: %d %v", rsp.StatusCode, w.Level())
+	}
+
+	rsp, err = http.Post(server.URL+"?level=warn", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK || l.Level() != log4go.LevelWarning {
+		t.Fatalf("调整全局级别失败: %d %v", rsp.StatusCode, l.Level())
+	}
+
+	rsp, err = http.Post(server.URL, "application/json", strings.NewReader(`{"writer":"none","level":"info"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusNotFound {
+		t.Fatalf("调整不存在的 Writer 应该返回 404, 实际返回 %d", rsp.StatusCode)
+	}
+
+	var db = l.GetLogger("app/db")
+	rsp, err = http.Post(server.URL, "application/json", strings.NewReader(`{"logger":"app/db","level":"error"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK || db.Level() != log4go.LevelError {
+		t.Fatalf("调整 Logger 级别失败: %d %v", rsp.StatusCode, db.Level())
+	}
+
+	// 不存在的 Logger 返回 404
+	rsp, err = http.Post(server.URL+"?logger=app/cache&level=error", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusNotFound {
+		t.Fatalf("调整不存在的 Logger 应该返回 404, 实际返回 %d", rsp.StatusCode)
+	}
+
+	l.AddWriter("legacy", &legacyWriter{})
+	rsp, err = http.Post(server.URL, "application/json", strings.NewReader(`{"writer":"legacy","level":"info"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("调整不支持 LevelSetter 的 Writer 应该返回 400, 实际返回 %d", rsp.StatusCode)
+	}
+	l.RemoveWriter("legacy")
+
+	rsp, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+
+	var status struct {
+		Level   log4go.Level        `json:"level"`
+		Writers []log4go.WriterInfo `json:"writers"`
+	}
+	if err = json.NewDecoder(rsp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Level != log4go.LevelWarning || len(status.Writers) != 1 || status.Writers[0].Name != "memory" || status.Writers[0].Filter == "" {
+		t.Fatalf("返回的状态信息错误: %+v", status)
+	}
+
+	// 未注册的级别也可以正常返回
+	rsp, err = http.Post(server.URL+"?writer=memory&level=15", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+	if err = json.NewDecoder(rsp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.StatusCode != http.StatusOK || w.Level() != log4go.LevelDebug+5 || status.Writers[0].Level != log4go.LevelDebug+5 {
+		t.Fatalf("返回的状态信息错误: %d %+v", rsp.StatusCode, status)
+	}
+}
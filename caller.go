@@ -0,0 +1,61 @@
+package log4go
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var helpers sync.Map
+
+// Helper 将调用该方法的函数标记为辅助函数，与 testing.T.Helper 类似，
+// 查找日志的调用位置时将跳过已标记的函数，如：
+//
+//	func logError(err error) {
+//		log4go.Helper()
+//		log4go.Errorln(err)
+//	}
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+	var frame, _ = runtime.CallersFrames(pcs[:]).Next()
+	if frame.Function != "" {
+		helpers.Store(frame.Function, struct{}{})
+	}
+}
+
+func isHelper(function string) bool {
+	_, ok := helpers.Load(function)
+	return ok
+}
+
+// callerFrame 返回 skip 层之后第一个不需要跳过的调用位置，skip 为 0 表示调用 callerFrame 的函数，
+// 辅助函数及 skipFn 返回 true 的函数将被跳过
+func callerFrame(skip int, skipFn func(function string) bool) (runtime.Frame, bool) {
+	var pcs [32]uintptr
+	var n = runtime.Callers(skip+2, pcs[:])
+	if n == 0 {
+		return runtime.Frame{}, false
+	}
+
+	var frames = runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isHelper(frame.Function) && (skipFn == nil || !skipFn(frame.Function)) {
+			return frame, true
+		}
+		if !more {
+			return frame, true
+		}
+	}
+}
+
+// shortFunction 去掉函数名中的包路径，如 github.com/smartwalle/log4go.(*logger).Info 返回 log4go.(*logger).Info
+func shortFunction(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		return function[i+1:]
+	}
+	return function
+}
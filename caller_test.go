@@ -0,0 +1,45 @@
+package log4go_test
+
+import (
+	"github.com/smartwalle/log4go"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func logWithHelper(l log4go.Logger, msg string) {
+	log4go.Helper()
+	l.Infoln(msg)
+}
+
+func logWithSkip(l log4go.Logger, msg string) {
+	l.Infoln(msg)
+}
+
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestLogger_Caller(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	var lines = []int{currentLine() + 1, currentLine() + 2, currentLine() + 3}
+	logWithHelper(l, "helper")
+	logWithSkip(l.With(log4go.WithCallerSkip(1)), "skip")
+	l.Infoln("direct")
+
+	var records = w.Records()
+	if len(records) != 3 {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+
+	for i, r := range records {
+		if filepath.Base(r.File) != "caller_test.go" || r.Line != lines[i] || !strings.HasSuffix(r.Function, "TestLogger_Caller") {
+			t.Fatalf("日志记录 %d 的调用位置错误: %s:%d %s", i, r.File, r.Line, r.Function)
+		}
+	}
+}
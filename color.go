@@ -0,0 +1,98 @@
+package log4go
+
+import (
+	"fmt"
+	"os"
+)
+
+//30 black		黑色
+//31 red		红色
+//32 green		绿色
+//33 yellow		黄色
+//34 blue		蓝色
+//35 magenta    洋红
+//36 cyan		天蓝色
+//37 white		白色
+
+// Color 为 ANSI SGR 参数，如 "1;31"，空字符串表示不着色
+type Color string
+
+const (
+	ColorNone    Color = ""
+	ColorBlack   Color = "1;30"
+	ColorRed     Color = "1;31"
+	ColorGreen   Color = "1;32"
+	ColorYellow  Color = "1;33"
+	ColorBlue    Color = "1;34"
+	ColorMagenta Color = "1;35"
+	ColorSkyBlue Color = "1;36"
+	ColorWhite   Color = "1;37"
+)
+
+// Color256 返回 256 色调色板中的前景色
+func Color256(n uint8) Color {
+	return Color(fmt.Sprintf("38;5;%d", n))
+}
+
+// ColorRGB 返回 24 位真彩色前景色
+func ColorRGB(r, g, b uint8) Color {
+	return Color(fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+}
+
+func (c Color) Wrap(s string) string {
+	if c == ColorNone || s == "" {
+		return s
+	}
+	return "\033[" + string(c) + "m" + s + "\033[0m"
+}
+
+type ColorMode int
+
+const (
+	ColorAuto   ColorMode = iota // 根据环境变量及终端类型自动判断
+	ColorAlways                  // 强制着色
+	ColorNever                   // 禁止着色
+)
+
+// Palette 描述 StdWriter 各部分使用的颜色，Levels 中未设置的级别使用注册时指定的颜色，
+// File 和 Message 默认不着色
+type Palette struct {
+	Levels  map[Level]Color
+	File    Color
+	Message Color
+}
+
+func DefaultPalette() *Palette {
+	return &Palette{Levels: make(map[Level]Color)}
+}
+
+func (this *Palette) clone() *Palette {
+	var p = &Palette{File: this.File, Message: this.Message}
+	p.Levels = make(map[Level]Color, len(this.Levels))
+	for level, c := range this.Levels {
+		p.Levels[level] = c
+	}
+	return p
+}
+
+func (this *Palette) Level(level Level) Color {
+	if this == nil {
+		return ColorNone
+	}
+	if c, ok := this.Levels[level]; ok {
+		return c
+	}
+	return level.Color()
+}
+
+// colorFromEnv 遵循 FORCE_COLOR 与 NO_COLOR 约定，ok 为 false 表示环境变量未做要求
+func colorFromEnv() (enable, ok bool) {
+	// 两个约定都要求值不为空，为空时视为未设置
+	if v := os.Getenv("FORCE_COLOR"); v != "" {
+		return v != "0" && v != "false", true
+	}
+	if v := os.Getenv("NO_COLOR"); v != "" {
+		return false, true
+	}
+	return false, false
+}
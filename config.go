@@ -0,0 +1,768 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/smartwalle/mail4go"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ConfigFormatJSON = "json"
+	ConfigFormatYAML = "yaml"
+)
+
+// Config 描述 Logger 及其 Writer 的配置，可以从 JSON 或者 YAML 文件中加载，如：
+//
+//	level: debug
+//	service: "[order]"
+//	writers:
+//	  - name: stdout
+//	    type: std
+//	    color: auto
+//	  - name: file
+//	    type: file
+//	    level: info
+//	    format: json
+//	    dir: ./logs
+//	    max_size: 50
+//	  - name: db
+//	    type: file
+//	    logger: app/db
+//	    dir: ./logs/db
+//	loggers:
+//	  - name: app/db
+//	    level: warning
+type Config struct {
+	Level    string          `json:"level" yaml:"level"`
+	Service  string          `json:"service" yaml:"service"`
+	Instance string          `json:"instance" yaml:"instance"`
+	Prefix   string          `json:"prefix" yaml:"prefix"`
+	Writers  []*WriterConfig `json:"writers" yaml:"writers"`
+	Loggers  []*LoggerConfig `json:"loggers" yaml:"loggers"`
+}
+
+type LoggerConfig struct {
+	Name   string `json:"name" yaml:"name"`
+	Level  string `json:"level" yaml:"level"`
+	Prefix string `json:"prefix" yaml:"prefix"`
+}
+
+type WriterConfig struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+	// Logger 为该 Writer 所属的命名 Logger，为空时添加到根 Logger
+	Logger string `json:"logger" yaml:"logger"`
+	Level  string `json:"level" yaml:"level"`
+
+	Format     string `json:"format" yaml:"format"`           // text 或者 json
+	LevelStyle string `json:"level_style" yaml:"level_style"` // short、full、lower 或者 syslog
+	TimeLayout string `json:"time_layout" yaml:"time_layout"`
+
+	Filter *FilterConfig `json:"filter" yaml:"filter"`
+
+	// std
+	Color string `json:"color" yaml:"color"` // auto、always 或者 never
+
+	// file
+	Dir     string `json:"dir" yaml:"dir"`
+	MaxSize int64  `json:"max_size" yaml:"max_size"` // 单位 MB
+	MaxAge  int64  `json:"max_age" yaml:"max_age"`   // 单位秒
+
+	// mail
+	Mail *MailWriterConfig `json:"mail" yaml:"mail"`
+
+	// syslog
+	Syslog *SyslogWriterConfig `json:"syslog" yaml:"syslog"`
+
+	// net
+	Net *NetWriterConfig `json:"net" yaml:"net"`
+
+	// http
+	HTTP *HTTPWriterConfig `json:"http" yaml:"http"`
+
+	// gelf
+	GELF *GELFWriterConfig `json:"gelf" yaml:"gelf"`
+
+	// 自定义 Writer 的配置信息
+	Options map[string]interface{} `json:"options" yaml:"options"`
+}
+
+// FilterConfig 中的各项条件同时满足时才允许，Service、Prefix、File 和 Message 为正则表达式
+type FilterConfig struct {
+	MinLevel string `json:"min_level" yaml:"min_level"`
+	MaxLevel string `json:"max_level" yaml:"max_level"`
+	Service  string `json:"service" yaml:"service"`
+	Prefix   string `json:"prefix" yaml:"prefix"`
+	File     string `json:"file" yaml:"file"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+type MailWriterConfig struct {
+	Host     string   `json:"host" yaml:"host"`
+	Port     string   `json:"port" yaml:"port"`
+	Username string   `json:"username" yaml:"username"`
+	Password string   `json:"password" yaml:"password"`
+	Secure   bool     `json:"secure" yaml:"secure"`
+	From     string   `json:"from" yaml:"from"`
+	To       []string `json:"to" yaml:"to"`
+	Subject  string   `json:"subject" yaml:"subject"`
+}
+
+// SyslogWriterConfig 中 Network 为空时连接本机的 syslog 服务
+type SyslogWriterConfig struct {
+	Network  string `json:"network" yaml:"network"` // udp、tcp、unix 或者 unixgram
+	Address  string `json:"address" yaml:"address"`
+	Facility string `json:"facility" yaml:"facility"`
+	Format   string `json:"format" yaml:"format"` // rfc5424 或者 rfc3164
+	AppName  string `json:"app_name" yaml:"app_name"`
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+type NetWriterConfig struct {
+	Network      string `json:"network" yaml:"network"` // tcp、udp、unix 或者 unixgram
+	Address      string `json:"address" yaml:"address"`
+	BufferSize   int    `json:"buffer_size" yaml:"buffer_size"`
+	SpillDir     string `json:"spill_dir" yaml:"spill_dir"`
+	SpillMaxSize int64  `json:"spill_max_size" yaml:"spill_max_size"` // 单位 MB
+}
+
+type HTTPWriterConfig struct {
+	URL           string            `json:"url" yaml:"url"`
+	Headers       map[string]string `json:"headers" yaml:"headers"`
+	BatchFormat   string            `json:"batch_format" yaml:"batch_format"` // ndjson 或者 array
+	Gzip          bool              `json:"gzip" yaml:"gzip"`
+	BatchSize     int               `json:"batch_size" yaml:"batch_size"`
+	FlushInterval int64             `json:"flush_interval" yaml:"flush_interval"` // 单位毫秒
+	MaxRetries    *int              `json:"max_retries" yaml:"max_retries"`
+	QueueSize     int               `json:"queue_size" yaml:"queue_size"`
+}
+
+type GELFWriterConfig struct {
+	Network     string `json:"network" yaml:"network"` // udp 或者 tcp
+	Address     string `json:"address" yaml:"address"`
+	Compression string `json:"compression" yaml:"compression"` // gzip、zlib 或者 none，只对 udp 有效
+	ChunkSize   int    `json:"chunk_size" yaml:"chunk_size"`
+	Host        string `json:"host" yaml:"host"`
+}
+
+var errEmptyConfig = errors.New("配置信息为空")
+
+// WriterFactory 根据配置信息创建 Writer，Level、Format 及 Filter 会在创建之后统一处理
+type WriterFactory func(cfg *WriterConfig) (Writer, error)
+
+var writerFactories = struct {
+	mu sync.RWMutex
+	m  map[string]WriterFactory
+}{
+	m: map[string]WriterFactory{
+		"std":    newStdWriterFromConfig,
+		"file":   newFileWriterFromConfig,
+		"mail":   newMailWriterFromConfig,
+		"syslog": newSyslogWriterFromConfig,
+		"net":    newNetWriterFromConfig,
+		"http":   newHTTPWriterFromConfig,
+		"gelf":   newGELFWriterFromConfig,
+	},
+}
+
+// RegisterWriterType 注册自定义的 Writer 类型，以便在配置文件中使用
+func RegisterWriterType(typ string, factory WriterFactory) {
+	writerFactories.mu.Lock()
+	defer writerFactories.mu.Unlock()
+	writerFactories.m[strings.ToLower(typ)] = factory
+}
+
+func lookupWriterFactory(typ string) WriterFactory {
+	writerFactories.mu.RLock()
+	defer writerFactories.mu.RUnlock()
+	return writerFactories.m[strings.ToLower(typ)]
+}
+
+// ReadConfig 读取配置文件，根据文件扩展名 .json、.yaml 或者 .yml 确定格式
+func ReadConfig(path string) (*Config, error) {
+	format, err := configFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeConfig(bytes.NewReader(data), format)
+}
+
+func configFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ConfigFormatJSON, nil
+	case ".yaml", ".yml":
+		return ConfigFormatYAML, nil
+	}
+	return "", fmt.Errorf("不支持的配置文件格式 %s", path)
+}
+
+// DecodeConfig 从 r 中读取并校验配置信息，format 为 json 或者 yaml。
+// 配置信息为空时（如编辑器保存文件时先将其清空）返回错误，避免应用之后移除所有的 Writer
+func DecodeConfig(r io.Reader, format string) (*Config, error) {
+	var cfg = &Config{}
+	var err error
+	switch strings.ToLower(format) {
+	case ConfigFormatJSON:
+		var decoder = json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(cfg)
+	case ConfigFormatYAML, "yml":
+		var decoder = yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		err = decoder.Decode(cfg)
+	default:
+		return nil, fmt.Errorf("不支持的配置格式 %s", format)
+	}
+	if err == io.EOF || (err == nil && reflect.DeepEqual(cfg, &Config{})) {
+		return nil, errEmptyConfig
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置信息出错: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadConfig 读取配置文件并应用到 SharedLogger
+func LoadConfig(path string) error {
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		return err
+	}
+	return cfg.Apply(sharedLogger)
+}
+
+// LoadConfigReader 从 r 中读取配置信息并应用到 SharedLogger
+func LoadConfigReader(r io.Reader, format string) error {
+	cfg, err := DecodeConfig(r, format)
+	if err != nil {
+		return err
+	}
+	return cfg.Apply(sharedLogger)
+}
+
+func (this *Config) Validate() error {
+	var errs []string
+	var check = func(path string, err error) {
+		if err != nil {
+			errs = append(errs, path+": "+err.Error())
+		}
+	}
+
+	check("level", validateLevel(this.Level))
+
+	var loggers = make(map[string]struct{}, len(this.Loggers))
+	for i, lc := range this.Loggers {
+		var path = fmt.Sprintf("loggers[%d]", i)
+		if lc == nil {
+			errs = append(errs, path+": 不能为空")
+			continue
+		}
+		var name = normalizeLoggerName(lc.Name)
+		if name == "" {
+			errs = append(errs, path+".name: 不能为空")
+		} else if _, ok := loggers[name]; ok {
+			errs = append(errs, path+".name: 重复的 Logger "+name)
+		}
+		loggers[name] = struct{}{}
+		check(path+".level", validateLevel(lc.Level))
+	}
+
+	var writers = make(map[string]struct{}, len(this.Writers))
+	for i, wc := range this.Writers {
+		var path = fmt.Sprintf("writers[%d]", i)
+		if wc == nil {
+			errs = append(errs, path+": 不能为空")
+			continue
+		}
+		if wc.Name == "" {
+			errs = append(errs, path+".name: 不能为空")
+		}
+		var key = wc.key()
+		if _, ok := writers[key]; ok {
+			errs = append(errs, path+".name: 重复的 Writer "+wc.Name)
+		}
+		writers[key] = struct{}{}
+
+		if lookupWriterFactory(wc.Type) == nil {
+			errs = append(errs, fmt.Sprintf("%s.type: 未知的 Writer 类型 %q", path, wc.Type))
+		}
+		check(path+".level", validateLevel(wc.Level))
+		if _, err := wc.formatter(); err != nil {
+			errs = append(errs, path+".format: "+err.Error())
+		}
+		if _, err := parseColorMode(wc.Color); err != nil {
+			errs = append(errs, path+".color: "+err.Error())
+		}
+		if wc.MaxSize < 0 {
+			errs = append(errs, path+".max_size: 不能小于 0")
+		}
+		if wc.MaxAge < 0 {
+			errs = append(errs, path+".max_age: 不能小于 0")
+		}
+		if wc.Filter != nil {
+			if _, err := wc.Filter.build(); err != nil {
+				errs = append(errs, path+".filter."+err.Error())
+			}
+		}
+		if strings.ToLower(wc.Type) == "mail" {
+			if wc.Mail == nil {
+				errs = append(errs, path+".mail: 不能为空")
+			} else {
+				if wc.Mail.Host == "" {
+					errs = append(errs, path+".mail.host: 不能为空")
+				}
+				if len(wc.Mail.To) == 0 {
+					errs = append(errs, path+".mail.to: 收件人信息不能为空")
+				}
+			}
+		}
+		if strings.ToLower(wc.Type) == "net" {
+			if wc.Net == nil {
+				errs = append(errs, path+".net: 不能为空")
+			} else {
+				if wc.Net.Network == "" {
+					errs = append(errs, path+".net.network: 不能为空")
+				}
+				if wc.Net.Address == "" {
+					errs = append(errs, path+".net.address: 不能为空")
+				}
+			}
+		}
+		if strings.ToLower(wc.Type) == "http" {
+			if wc.HTTP == nil || wc.HTTP.URL == "" {
+				errs = append(errs, path+".http.url: 不能为空")
+			} else if _, err := ParseHTTPBatchFormat(wc.HTTP.BatchFormat); err != nil {
+				errs = append(errs, path+".http.batch_format: "+err.Error())
+			}
+		}
+		if strings.ToLower(wc.Type) == "gelf" {
+			if wc.GELF == nil || wc.GELF.Address == "" {
+				errs = append(errs, path+".gelf.address: 不能为空")
+			} else if _, err := ParseGELFCompression(wc.GELF.Compression); err != nil {
+				errs = append(errs, path+".gelf.compression: "+err.Error())
+			}
+		}
+		if wc.Syslog != nil {
+			if wc.Syslog.Facility != "" {
+				if _, err := ParseSyslogFacility(wc.Syslog.Facility); err != nil {
+					errs = append(errs, path+".syslog.facility: "+err.Error())
+				}
+			}
+			if _, err := ParseSyslogFormat(wc.Syslog.Format); err != nil {
+				errs = append(errs, path+".syslog.format: "+err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New("配置信息错误: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Apply 将配置应用到 l 及其命名 Logger，l 中已有但配置中不存在的 Writer 将被移除并关闭
+func (this *Config) Apply(l Logger) error {
+	return this.apply(l, nil)
+}
+
+// apply 将配置应用到 l，prev 为上一次应用的配置，与 prev 中完全相同的 Writer 将被保留，不会重新创建
+func (this *Config) apply(l Logger, prev *Config) error {
+	root, ok := l.(*logger)
+	if !ok {
+		return fmt.Errorf("不支持的 Logger 类型 %T", l)
+	}
+
+	if err := this.Validate(); err != nil {
+		return err
+	}
+
+	var prevWriters = make(map[string]*WriterConfig)
+	var prevLoggers = make(map[string]*LoggerConfig)
+	if prev != nil {
+		for _, wc := range prev.Writers {
+			prevWriters[wc.key()] = wc
+		}
+		for _, lc := range prev.Loggers {
+			prevLoggers[normalizeLoggerName(lc.Name)] = lc
+		}
+	}
+
+	var current = make(map[string]*writerEntry)
+	root.walk(func(l *logger) {
+		for _, entry := range l.namedEntries() {
+			current[normalizeLoggerName(l.name)+kLoggerSeparator+entry.name] = entry.writerEntry
+		}
+	})
+
+	var created []*writerEntry
+	var reused = make(map[*writerEntry]struct{})
+	var writers = make(map[*logger]map[string]*writerEntry)
+	for _, wc := range this.Writers {
+		var key = wc.key()
+		var entry = current[key]
+		if pwc, ok := prevWriters[key]; ok && entry != nil && reflect.DeepEqual(pwc, wc) {
+			reused[entry] = struct{}{}
+		} else {
+			var err error
+			if entry, err = wc.build(); err != nil {
+				closeWriters(created...)
+				return fmt.Errorf("创建 Writer %s 出错: %v", wc.Name, err)
+			}
+			created = append(created, entry)
+		}
+
+		var target = root.GetLogger(wc.Logger).(*logger)
+		if writers[target] == nil {
+			writers[target] = make(map[string]*writerEntry)
+		}
+		writers[target][wc.Name] = entry
+	}
+
+	// 持有写锁，保证之后的日志看到的是完整的新配置，被替换的 Writer 在正在进行的日志写入完成之后关闭
+	root.tree.Lock()
+
+	applyLevel(root, this.Level, prev != nil && prev.Level != "")
+	if this.Service != "" || (prev != nil && prev.Service != "") {
+		root.SetService(this.Service)
+	}
+	if this.Instance != "" || (prev != nil && prev.Instance != "") {
+		root.SetInstance(this.Instance)
+	}
+	if this.Prefix != "" || (prev != nil && prev.Prefix != "") {
+		root.SetPrefix(this.Prefix)
+	}
+
+	for _, lc := range this.Loggers {
+		var name = normalizeLoggerName(lc.Name)
+		var plc = prevLoggers[name]
+		delete(prevLoggers, name)
+
+		var child = root.GetLogger(name)
+		applyLevel(child, lc.Level, plc != nil && plc.Level != "")
+		if lc.Prefix != "" || (plc != nil && plc.Prefix != "") {
+			child.SetPrefix(lc.Prefix)
+		}
+	}
+	// 已从配置中移除的 Logger 恢复为沿用父 Logger 的设置
+	for name, plc := range prevLoggers {
+		var child = root.GetLogger(name)
+		if plc.Level != "" {
+			child.ResetLevel()
+		}
+		if plc.Prefix != "" {
+			child.SetPrefix("")
+		}
+	}
+
+	var removed []*writerEntry
+	root.walk(func(l *logger) {
+		for _, entry := range l.setWriters(writers[l]) {
+			if _, ok := reused[entry]; !ok {
+				removed = append(removed, entry)
+			}
+		}
+	})
+
+	root.tree.Unlock()
+
+	closeWriters(removed...)
+	return nil
+}
+
+func applyLevel(l Logger, s string, reset bool) {
+	if s != "" {
+		level, _ := ParseLevel(s)
+		l.SetLevel(level)
+	} else if reset {
+		l.ResetLevel()
+	}
+}
+
+func (this *WriterConfig) key() string {
+	return normalizeLoggerName(this.Logger) + kLoggerSeparator + this.Name
+}
+
+func (this *WriterConfig) build() (*writerEntry, error) {
+	w, err := lookupWriterFactory(this.Type)(this)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return nil, errors.New("Writer 为空")
+	}
+
+	if this.Level != "" {
+		ls, ok := w.(LevelSetter)
+		if !ok {
+			w.Close()
+			return nil, fmt.Errorf("Writer 类型 %s 不支持设置级别", this.Type)
+		}
+		level, _ := ParseLevel(this.Level)
+		ls.SetLevel(level)
+	}
+
+	// std 的文本格式由 newStdWriterFromConfig 处理，以保留颜色
+	if _, isStd := w.(*StdWriter); !isStd || strings.ToLower(this.Format) == ConfigFormatJSON {
+		if f, _ := this.formatter(); f != nil {
+			if fs, ok := w.(interface{ SetFormatter(Formatter) }); ok {
+				fs.SetFormatter(f)
+			}
+		}
+	}
+
+	var entry = newWriterEntry(this.Name, w)
+	if this.Filter != nil {
+		entry.filter, _ = this.Filter.build()
+	}
+	return entry, nil
+}
+
+// formatter 返回配置中指定的 Formatter，未指定格式信息时返回 nil
+func (this *WriterConfig) formatter() (Formatter, error) {
+	if this.Format == "" && this.LevelStyle == "" && this.TimeLayout == "" {
+		return nil, nil
+	}
+
+	var style LevelStyle
+	if this.LevelStyle != "" {
+		var err error
+		if style, err = ParseLevelStyle(this.LevelStyle); err != nil {
+			return nil, err
+		}
+	}
+
+	switch strings.ToLower(this.Format) {
+	case "", "text":
+		var f = NewTextFormatter()
+		if this.LevelStyle != "" {
+			f.LevelStyle = style
+		}
+		if this.TimeLayout != "" {
+			f.TimeLayout = this.TimeLayout
+		}
+		return f, nil
+	case ConfigFormatJSON:
+		var f = NewJSONFormatter()
+		if this.LevelStyle != "" {
+			f.LevelStyle = style
+		}
+		if this.TimeLayout != "" {
+			f.TimeLayout = this.TimeLayout
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("未知的格式 %q", this.Format)
+}
+
+func (this *FilterConfig) build() (Filter, error) {
+	var filters []Filter
+
+	if this.MinLevel != "" || this.MaxLevel != "" {
+		var min, max = LevelTrace, Level(kLevelMax)
+		var err error
+		if this.MinLevel != "" {
+			if min, err = ParseLevel(this.MinLevel); err != nil {
+				return nil, fmt.Errorf("min_level: %v", err)
+			}
+		}
+		if this.MaxLevel != "" {
+			if max, err = ParseLevel(this.MaxLevel); err != nil {
+				return nil, fmt.Errorf("max_level: %v", err)
+			}
+		}
+		filters = append(filters, LevelRange(min, max))
+	}
+
+	var matches = []struct {
+		name string
+		expr string
+		fn   func(*regexp.Regexp) Filter
+	}{
+		{"service", this.Service, ServiceMatches},
+		{"prefix", this.Prefix, PrefixMatches},
+		{"file", this.File, FileMatches},
+		{"message", this.Message, MessageMatches},
+	}
+	for _, m := range matches {
+		if m.expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(m.expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", m.name, err)
+		}
+		filters = append(filters, m.fn(re))
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return And(filters...), nil
+}
+
+func validateLevel(s string) error {
+	if s == "" {
+		return nil
+	}
+	_, err := ParseLevel(s)
+	return err
+}
+
+func parseColorMode(s string) (ColorMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return ColorAuto, nil
+	case "always", "on", "true":
+		return ColorAlways, nil
+	case "never", "off", "false":
+		return ColorNever, nil
+	}
+	return ColorAuto, fmt.Errorf("未知的颜色模式 %q", s)
+}
+
+func newStdWriterFromConfig(cfg *WriterConfig) (Writer, error) {
+	mode, err := parseColorMode(cfg.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts = []StdWriterOption{WithColor(mode)}
+	if cfg.LevelStyle != "" {
+		style, err := ParseLevelStyle(cfg.LevelStyle)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithLevelStyle(style))
+	}
+
+	var sw = NewStdWriter(LevelTrace, opts...)
+	if cfg.TimeLayout != "" && strings.ToLower(cfg.Format) != ConfigFormatJSON {
+		var tf = NewTextFormatter()
+		tf.LevelStyle = sw.levelStyle
+		tf.TimeLayout = cfg.TimeLayout
+		if sw.ColorEnabled() {
+			tf.Palette = sw.palette
+		}
+		sw.SetFormatter(tf)
+	}
+	return sw, nil
+}
+
+func newFileWriterFromConfig(cfg *WriterConfig) (Writer, error) {
+	var fw = NewFileWriter(LevelTrace, WithLogDir(cfg.Dir), WithMaxSize(cfg.MaxSize), WithMaxAge(cfg.MaxAge))
+	if fw == nil {
+		return nil, fmt.Errorf("无法创建日志目录 %s", cfg.Dir)
+	}
+	return fw, nil
+}
+
+func newMailWriterFromConfig(cfg *WriterConfig) (Writer, error) {
+	var mc = cfg.Mail
+	var mw = NewMailWriter(LevelTrace)
+	mw.SetMailConfig(&mail4go.MailConfig{
+		Username: mc.Username,
+		Host:     mc.Host,
+		Password: mc.Password,
+		Port:     mc.Port,
+		Secure:   mc.Secure,
+	})
+	mw.SetFrom(mc.From)
+	mw.SetToMail(mc.To...)
+	mw.SetSubject(mc.Subject)
+	return mw, nil
+}
+
+func newSyslogWriterFromConfig(cfg *WriterConfig) (Writer, error) {
+	var sc = cfg.Syslog
+	if sc == nil {
+		sc = &SyslogWriterConfig{}
+	}
+
+	var opts = []SyslogWriterOption{WithAppName(sc.AppName), WithHostname(sc.Hostname)}
+	if sc.Facility != "" {
+		facility, err := ParseSyslogFacility(sc.Facility)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithFacility(facility))
+	}
+	format, err := ParseSyslogFormat(sc.Format)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithSyslogFormat(format))
+	return NewSyslogWriter(LevelTrace, sc.Network, sc.Address, opts...), nil
+}
+
+func newNetWriterFromConfig(cfg *WriterConfig) (Writer, error) {
+	var nc = cfg.Net
+	var opts = []NetWriterOption{WithNetBufferSize(nc.BufferSize)}
+	if nc.SpillDir != "" {
+		opts = append(opts, WithNetSpillDir(nc.SpillDir, nc.SpillMaxSize))
+	}
+	var nw = NewNetWriter(LevelTrace, nc.Network, nc.Address, opts...)
+	if nw == nil {
+		return nil, fmt.Errorf("无法创建目录 %s", nc.SpillDir)
+	}
+	return nw, nil
+}
+
+func newHTTPWriterFromConfig(cfg *WriterConfig) (Writer, error) {
+	var hc = cfg.HTTP
+	format, err := ParseHTTPBatchFormat(hc.BatchFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts = []HTTPWriterOption{
+		WithHTTPBatchFormat(format),
+		WithHTTPBatch(hc.BatchSize, time.Duration(hc.FlushInterval)*time.Millisecond),
+		WithHTTPQueueSize(hc.QueueSize),
+	}
+	for key, value := range hc.Headers {
+		opts = append(opts, WithHTTPHeader(key, value))
+	}
+	if hc.Gzip {
+		opts = append(opts, WithHTTPGzip())
+	}
+	if hc.MaxRetries != nil {
+		opts = append(opts, WithHTTPRetry(*hc.MaxRetries, 0, 0))
+	}
+	return NewHTTPWriter(LevelTrace, hc.URL, opts...), nil
+}
+
+func newGELFWriterFromConfig(cfg *WriterConfig) (Writer, error) {
+	var gc = cfg.GELF
+	compression, err := ParseGELFCompression(gc.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	var network = gc.Network
+	if network == "" {
+		network = "udp"
+	}
+	var opts = []GELFWriterOption{WithGELFCompression(compression), WithGELFChunkSize(gc.ChunkSize), WithGELFHost(gc.Host)}
+	return NewGELFWriter(LevelTrace, network, gc.Address, opts...), nil
+}
@@ -0,0 +1,132 @@
+package log4go_test
+
+import (
+	"github.com/smartwalle/log4go"
+	"os"
+	"strings"
+	"testing"
+)
+
+var configWriters = map[string]*memoryWriter{}
+
+func init() {
+	log4go.RegisterWriterType("memory", func(cfg *log4go.WriterConfig) (log4go.Writer, error) {
+		var w = &memoryWriter{}
+		configWriters[cfg.Name] = w
+		return w, nil
+	})
+	log4go.RegisterWriterType("legacy", func(cfg *log4go.WriterConfig) (log4go.Writer, error) {
+		return &legacyWriter{}, nil
+	})
+}
+
+func TestConfig_Apply(t *testing.T) {
+	var data = `
+level: debug
+service: "[svc]"
+writers:
+  - name: all
+    type: memory
+  - name: errors
+    type: memory
+    level: error
+    format: json
+    filter:
+      message: "^payment"
+  - name: db
+    type: memory
+    logger: app/db
+loggers:
+  - name: app/db
+    level: warning
+`
+	cfg, err := log4go.DecodeConfig(strings.NewReader(data), log4go.ConfigFormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var l = log4go.New()
+	var stdout = &memoryWriter{}
+	l.AddWriter("stdout", stdout)
+	if err = cfg.Apply(l); err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Writer("stdout") != nil {
+		t.Fatal("配置中不存在的 Writer 应该被移除")
+	}
+	if l.Level() != log4go.LevelDebug || l.GetLogger("app/db").Level() != log4go.LevelWarning {
+		t.Fatalf("日志级别错误: %v %v", l.Level(), l.GetLogger("app/db").Level())
+	}
+
+	l.Traceln("trace")
+	l.Debugln("debug")
+	l.WriteMessage(1, log4go.LevelError, "payment failed\n")
+	l.WriteMessage(1, log4go.LevelError, "order failed\n")
+	l.GetLogger("app/db").Infoln("db info")
+	l.GetLogger("app/db").Warnln("db warn")
+
+	if records := configWriters["all"].Records(); len(records) != 4 || records[0].Service != "[svc]" {
+		t.Fatalf("all 收到的日志记录错误: %v", records)
+	}
+	if records := configWriters["errors"].Records(); len(records) != 1 || records[0].Message != "payment failed\n" {
+		t.Fatalf("errors 收到的日志记录错误: %v", records)
+	}
+	if records := configWriters["db"].Records(); len(records) != 1 || records[0].Level != log4go.LevelWarning {
+		t.Fatalf("db 收到的日志记录错误: %v", records)
+	}
+}
+
+func TestConfig_ApplyLevelSetter(t *testing.T) {
+	var cfg = &log4go.Config{Writers: []*log4go.WriterConfig{{Name: "legacy", Type: "legacy"}}}
+	var l = log4go.New()
+	if err := cfg.Apply(l); err != nil || l.Writer("legacy") == nil {
+		t.Fatalf("未设置级别时应该可以使用未实现 LevelSetter 的 Writer: %v", err)
+	}
+
+	cfg = &log4go.Config{Writers: []*log4go.WriterConfig{{Name: "legacy", Type: "legacy", Level: "info"}}}
+	if err := cfg.Apply(l); err == nil || !strings.Contains(err.Error(), "不支持设置级别") {
+		t.Fatalf("Writer 未实现 LevelSetter 时设置级别应该返回错误: %v", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	var data = `{
+		"level": "verbose",
+		"writers": [
+			{"name": "a", "type": "std", "color": "rainbow"},
+			{"name": "a", "type": "kafka"},
+			{"name": "b", "type": "file", "format": "xml", "filter": {"file": "("}},
+			{"name": "c", "type": "mail"}
+		]
+	}`
+	_, err := log4go.DecodeConfig(strings.NewReader(data), log4go.ConfigFormatJSON)
+	if err == nil {
+		t.Fatal("错误的配置信息应该返回错误")
+	}
+
+	for _, path := range []string{"level:", "writers[0].color:", "writers[1].name:", "writers[1].type:", "writers[2].format:", "writers[2].filter.file:", "writers[3].mail:"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Fatalf("错误信息中缺少 %s: %v", path, err)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	os.Setenv(log4go.EnvLevel, "warn")
+	os.Setenv(log4go.EnvFormat, "json")
+	os.Setenv(log4go.EnvNoColor, "1")
+	defer func() {
+		os.Unsetenv(log4go.EnvLevel)
+		os.Unsetenv(log4go.EnvFormat)
+		os.Unsetenv(log4go.EnvNoColor)
+	}()
+
+	var cfg = log4go.ConfigFromEnv()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "warn" || len(cfg.Writers) != 1 || cfg.Writers[0].Format != "json" || cfg.Writers[0].Color != "never" {
+		t.Fatalf("根据环境变量生成的配置信息错误: %+v %+v", cfg, cfg.Writers[0])
+	}
+}
@@ -0,0 +1,234 @@
+package log4go
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor 从 context.Context 中提取需要添加到日志记录中的字段，如请求 ID、用户 ID 等
+type ContextExtractor func(ctx context.Context) []Field
+
+type loggerKey struct{}
+
+type fieldsKey struct{}
+
+var contextExtractors = struct {
+	mu         sync.RWMutex
+	extractors []*ContextExtractor
+}{}
+
+// RegisterContextExtractor 注册 ContextExtractor，所有带 context.Context 的日志方法都会调用已注册的 ContextExtractor，
+// 返回用于取消注册的函数
+func RegisterContextExtractor(fn ContextExtractor) (unregister func()) {
+	if fn == nil {
+		return func() {}
+	}
+	var entry = &fn
+
+	contextExtractors.mu.Lock()
+	defer contextExtractors.mu.Unlock()
+	// 复制一份，已经取得的列表不受影响
+	var extractors = make([]*ContextExtractor, 0, len(contextExtractors.extractors)+1)
+	extractors = append(extractors, contextExtractors.extractors...)
+	contextExtractors.extractors = append(extractors, entry)
+
+	return func() {
+		contextExtractors.mu.Lock()
+		defer contextExtractors.mu.Unlock()
+		var extractors = make([]*ContextExtractor, 0, len(contextExtractors.extractors))
+		for _, e := range contextExtractors.extractors {
+			if e != entry {
+				extractors = append(extractors, e)
+			}
+		}
+		contextExtractors.extractors = extractors
+	}
+}
+
+// NewContext 返回携带 l 的 context.Context，ctx 为 nil 时使用 context.Background()
+func NewContext(ctx context.Context, l Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext 返回 ctx 中携带的 Logger，没有时返回 SharedLogger
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(Logger); ok && l != nil {
+			return l
+		}
+	}
+	return sharedLogger
+}
+
+// ContextWithFields 返回携带 fields 的 context.Context，fields 会追加到 ctx 中已有的字段之后，
+// ctx 为 nil 时使用 context.Background()
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var exists, _ = ctx.Value(fieldsKey{}).([]Field)
+	var nFields = make([]Field, 0, len(exists)+len(fields))
+	nFields = append(nFields, exists...)
+	nFields = append(nFields, fields...)
+	return context.WithValue(ctx, fieldsKey{}, nFields)
+}
+
+func fieldsFromContext(ctx context.Context) []Field {
+	var fields, _ = ctx.Value(fieldsKey{}).([]Field)
+	fields = fields[:len(fields):len(fields)]
+
+	contextExtractors.mu.RLock()
+	var extractors = contextExtractors.extractors
+	contextExtractors.mu.RUnlock()
+	for _, fn := range extractors {
+		fields = append(fields, (*fn)(ctx)...)
+	}
+	return fields
+}
+
+func (this *logger) TraceCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	this.output(ctx, 2, LevelTrace, msg, fields)
+}
+
+func (this *logger) TraceCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	this.output(ctx, 2, LevelTrace, msg, fields)
+}
+
+func (this *logger) DebugCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	this.output(ctx, 2, LevelDebug, msg, fields)
+}
+
+func (this *logger) DebugCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	this.output(ctx, 2, LevelDebug, msg, fields)
+}
+
+func (this *logger) InfoCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	this.output(ctx, 2, LevelInfo, msg, fields)
+}
+
+func (this *logger) InfoCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	this.output(ctx, 2, LevelInfo, msg, fields)
+}
+
+func (this *logger) WarnCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	this.output(ctx, 2, LevelWarning, msg, fields)
+}
+
+func (this *logger) WarnCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	this.output(ctx, 2, LevelWarning, msg, fields)
+}
+
+func (this *logger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	this.output(ctx, 2, LevelError, msg, fields)
+}
+
+func (this *logger) ErrorCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	this.output(ctx, 2, LevelError, msg, fields)
+}
+
+func (this *logger) PanicCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	this.output(ctx, 2, LevelPanic, msg, fields)
+	panic(msg)
+}
+
+func (this *logger) PanicCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	this.output(ctx, 2, LevelPanic, msg, fields)
+	panic(msg)
+}
+
+func (this *logger) FatalCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	this.output(ctx, 2, LevelFatal, msg, fields)
+}
+
+func (this *logger) FatalCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	this.output(ctx, 2, LevelFatal, msg, fields)
+}
+
+func TraceCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelTrace, msg, fields...)
+}
+
+func TraceCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelTrace, msg, fields...)
+}
+
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelDebug, msg, fields...)
+}
+
+func DebugCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelDebug, msg, fields...)
+}
+
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelInfo, msg, fields...)
+}
+
+func InfoCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelInfo, msg, fields...)
+}
+
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelWarning, msg, fields...)
+}
+
+func WarnCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelWarning, msg, fields...)
+}
+
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelError, msg, fields...)
+}
+
+func ErrorCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelError, msg, fields...)
+}
+
+func PanicCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelPanic, msg, fields...)
+	panic(msg)
+}
+
+func PanicCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelPanic, msg, fields...)
+	panic(msg)
+}
+
+func FatalCtx(ctx context.Context, args ...interface{}) {
+	var msg, fields = sprintln(args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelFatal, msg, fields...)
+}
+
+func FatalCtxf(ctx context.Context, format string, args ...interface{}) {
+	var msg, fields = sprintf(format, args)
+	FromContext(ctx).WriteMessageContext(ctx, 2, LevelFatal, msg, fields...)
+}
@@ -0,0 +1,89 @@
+package log4go_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type requestIdKey struct{}
+
+func TestContext(t *testing.T) {
+	var unregister = log4go.RegisterContextExtractor(func(ctx context.Context) []log4go.Field {
+		if id, ok := ctx.Value(requestIdKey{}).(string); ok {
+			return []log4go.Field{log4go.Any("request_id", id)}
+		}
+		return nil
+	})
+	defer unregister()
+
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	var ctx = log4go.NewContext(context.Background(), l)
+	ctx = context.WithValue(ctx, requestIdKey{}, "r-1")
+	ctx = log4go.ContextWithFields(ctx, log4go.Any("user_id", 10))
+
+	if log4go.FromContext(ctx) != l || log4go.FromContext(context.Background()) != log4go.SharedLogger() {
+		t.Fatal("FromContext 返回的 Logger 错误")
+	}
+
+	log4go.InfoCtx(ctx, "hello")
+	l.WarnCtxf(ctx, "hello %s", "world")
+
+	var records = w.Records()
+	if len(records) != 2 {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+	for _, r := range records {
+		if len(r.Fields) != 2 || r.Fields[0].String() != "user_id=10" || r.Fields[1].String() != "request_id=r-1" {
+			t.Fatalf("日志记录中的字段错误: %v", r.Fields)
+		}
+		if filepath.Base(r.File) != "context_test.go" || r.Context != ctx {
+			t.Fatalf("日志记录信息错误: %s %v", r.File, r.Context)
+		}
+	}
+
+	var text = string(log4go.NewTextFormatter().Format(&records[0]))
+	if !strings.HasSuffix(text, "hello user_id=10 request_id=r-1\n") {
+		t.Fatalf("文本格式错误: %q", text)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(log4go.NewJSONFormatter().Format(&records[1]), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["msg"] != "hello world" || obj["user_id"] != float64(10) || obj["request_id"] != "r-1" || obj["level"] != "warning" {
+		t.Fatalf("JSON 格式错误: %v", obj)
+	}
+	// 取消注册之后不再提取字段，重复调用不会出错
+	unregister()
+	unregister()
+	l.InfoCtx(ctx, "after")
+	if records = w.Records(); len(records) != 3 || len(records[2].Fields) != 1 || records[2].Fields[0].String() != "user_id=10" {
+		t.Fatalf("取消注册之后的字段错误: %v", records[2].Fields)
+	}
+}
+
+func TestContext_Nil(t *testing.T) {
+	var l = log4go.New()
+	var ctx = log4go.ContextWithFields(nil, log4go.Any("request_id", "r-1"))
+	ctx = log4go.NewContext(ctx, l)
+	if log4go.FromContext(ctx) != l {
+		t.Fatal("FromContext 返回的 Logger 错误")
+	}
+	if ctx = log4go.NewContext(nil, l); log4go.FromContext(ctx) != l {
+		t.Fatal("FromContext 返回的 Logger 错误")
+	}
+
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+	l.InfoCtx(log4go.ContextWithFields(nil, log4go.Any("request_id", "r-1")), "hello")
+	if records := w.Records(); len(records) != 1 || len(records[0].Fields) == 0 || records[0].Fields[0].String() != "request_id=r-1" {
+		t.Fatalf("日志记录错误: %+v", records)
+	}
+}
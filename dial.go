@@ -0,0 +1,88 @@
+package log4go
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// retryConn 为 SyslogWriter、GELFWriter 等 Writer 的连接，第一次写入时建立连接，写入失败时重新连接并再尝试一次，
+// 连接失败之后在等待时间内不再尝试连接，避免服务不可用时每次写入日志都阻塞在连接上。调用方需要自己加锁
+type retryConn struct {
+	conn    net.Conn
+	backoff dialBackoff
+}
+
+// write 在没有连接时调用 dial 建立连接，然后调用 write 写入
+func (this *retryConn) write(dial func() (net.Conn, error), write func(conn net.Conn) (int, error)) (n int, err error) {
+	// 连接断开时重新连接并再尝试一次
+	for i := 0; i < 2; i++ {
+		if this.conn == nil {
+			if err = this.backoff.check(); err != nil {
+				return 0, err
+			}
+			var conn net.Conn
+			if conn, err = dial(); err != nil {
+				this.backoff.fail(err)
+				return 0, err
+			}
+			this.conn = conn
+			this.backoff.reset()
+		}
+		if n, err = write(this.conn); err == nil {
+			return n, nil
+		}
+		this.close()
+	}
+	return n, err
+}
+
+func (this *retryConn) close() error {
+	var err error
+	if this.conn != nil {
+		err = this.conn.Close()
+	}
+	this.conn = nil
+	return err
+}
+
+// dialBackoff 记录连接失败的时间，从 min 开始每次失败后加倍，最大为 max
+type dialBackoff struct {
+	min   time.Duration
+	max   time.Duration
+	delay time.Duration
+	next  time.Time
+	err   error
+}
+
+// check 在等待时间内时返回上一次连接失败的错误
+func (this *dialBackoff) check() error {
+	if this.err != nil && time.Now().Before(this.next) {
+		return fmt.Errorf("连接失败，%v 之后重试: %v", this.next.Sub(time.Now()).Round(time.Millisecond), this.err)
+	}
+	return nil
+}
+
+func (this *dialBackoff) fail(err error) {
+	if this.delay *= 2; this.delay < this.min {
+		this.delay = this.min
+	}
+	if this.delay > this.max {
+		this.delay = this.max
+	}
+	this.next = time.Now().Add(this.delay)
+	this.err = err
+}
+
+func (this *dialBackoff) reset() {
+	this.delay = 0
+	this.err = nil
+}
+
+func isStreamNetwork(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package log4go
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	EnvLevel   = "LOG4GO_LEVEL"    // 全局日志级别，如 debug
+	EnvFormat  = "LOG4GO_FORMAT"   // 输出格式，text 或者 json
+	EnvFileDir = "LOG4GO_FILE_DIR" // 不为空时同时将日志写入该目录
+	EnvNoColor = "LOG4GO_NO_COLOR" // 不为空且不为 0 或者 false 时禁止 stdout 着色
+)
+
+// ConfigFromEnv 根据环境变量生成配置信息，SharedLogger 在初始化时会应用该配置
+func ConfigFromEnv() *Config {
+	var cfg = &Config{}
+	cfg.Level = strings.TrimSpace(os.Getenv(EnvLevel))
+
+	var format = strings.ToLower(strings.TrimSpace(os.Getenv(EnvFormat)))
+
+	var stdout = &WriterConfig{Name: "stdout", Type: "std", Format: format}
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv(EnvNoColor))); v != "" && v != "0" && v != "false" {
+		stdout.Color = "never"
+	}
+	cfg.Writers = append(cfg.Writers, stdout)
+
+	if dir := strings.TrimSpace(os.Getenv(EnvFileDir)); dir != "" {
+		cfg.Writers = append(cfg.Writers, &WriterConfig{Name: "file", Type: "file", Format: format, Dir: dir})
+	}
+	return cfg
+}
+
+// configureFromEnv 环境变量有误时回退到默认的 stdout 输出，并在 stderr 中输出错误信息
+func configureFromEnv(l Logger) {
+	if err := ConfigFromEnv().Apply(l); err != nil {
+		fmt.Fprintf(os.Stderr, "log4go: 忽略环境变量中的日志配置, %v\n", err)
+		l.AddWriter("stdout", NewStdWriter(LevelTrace))
+	}
+}
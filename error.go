@@ -0,0 +1,127 @@
+package log4go
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const (
+	kErrorKey      = "error"
+	kErrorMaxChain = 32
+)
+
+// ErrorDetail 为 Err 记录的错误信息
+type ErrorDetail struct {
+	Message string       `json:"msg"`
+	Type    string       `json:"type"`
+	Chain   []ErrorCause `json:"chain,omitempty"` // 通过 Unwrap 或者 Cause 得到的底层错误，由外向内排列
+	Stack   []StackFrame `json:"stack,omitempty"` // 最内层带有 StackTrace 方法的错误的调用栈
+}
+
+// ErrorCause 为错误链中的一个错误
+type ErrorCause struct {
+	Message string `json:"msg"`
+	Type    string `json:"type"`
+}
+
+// Err 将 err 作为键为 error 的字段，可以直接作为日志方法的参数，如：log4go.Errorln("读取配置失败", log4go.Err(err))
+func Err(err error) Field {
+	return NamedErr(kErrorKey, err)
+}
+
+// NamedErr 与 Err 相同，使用 key 作为字段的键
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Key: key, Value: nil}
+	}
+	return Field{Key: key, Value: newErrorDetail(err)}
+}
+
+func newErrorDetail(err error) *ErrorDetail {
+	var detail = &ErrorDetail{}
+	detail.Message = err.Error()
+	detail.Type = errorType(err)
+	detail.Stack = errorStack(err)
+
+	for i, cause := 0, unwrapError(err); cause != nil && i < kErrorMaxChain; i, cause = i+1, unwrapError(cause) {
+		detail.Chain = append(detail.Chain, ErrorCause{Message: cause.Error(), Type: errorType(cause)})
+		if stack := errorStack(cause); len(stack) > 0 {
+			detail.Stack = stack
+		}
+	}
+	return detail
+}
+
+func errorType(err error) string {
+	return fmt.Sprintf("%T", err)
+}
+
+// unwrapError 支持标准库的 Unwrap 和 github.com/pkg/errors 的 Cause
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	}
+	return nil
+}
+
+// errorStack 获取错误携带的调用栈，StackTrace 方法的返回值可以是 []StackFrame，
+// 也可以是由程序计数器组成的切片，如 github.com/pkg/errors 的 StackTrace
+func errorStack(err error) []StackFrame {
+	var method = reflect.ValueOf(err).MethodByName("StackTrace")
+	if method.IsValid() == false || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	var result = method.Call(nil)[0]
+	if stack, ok := result.Interface().([]StackFrame); ok {
+		return stack
+	}
+	if result.Kind() != reflect.Slice || result.Type().Elem().Kind() != reflect.Uintptr || result.Len() == 0 {
+		return nil
+	}
+
+	var pcs = make([]uintptr, result.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(result.Index(i).Uint())
+	}
+
+	var stack = make([]StackFrame, 0, len(pcs))
+	var frames = runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// text 以 key=value 的形式输出错误信息，错误链合并为一个字段，调用栈由 TextFormatter 输出在日志之后
+func (this *ErrorDetail) text(key string) string {
+	var buf strings.Builder
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(fieldText(this.Message))
+	buf.WriteByte(' ')
+	buf.WriteString(key)
+	buf.WriteString(".type=")
+	buf.WriteString(fieldText(this.Type))
+
+	if len(this.Chain) > 0 {
+		var causes = make([]string, 0, len(this.Chain))
+		for _, cause := range this.Chain {
+			causes = append(causes, cause.Type+": "+cause.Message)
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(key)
+		buf.WriteString(".chain=")
+		buf.WriteString(fieldText(strings.Join(causes, "; ")))
+	}
+	return buf.String()
+}
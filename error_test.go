@@ -0,0 +1,83 @@
+package log4go_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/smartwalle/log4go"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newStackError(msg string) *stackError {
+	var pcs = make([]uintptr, 8)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	return &stackError{msg: msg, pcs: pcs}
+}
+
+func (this *stackError) Error() string {
+	return this.msg
+}
+
+func (this *stackError) StackTrace() []uintptr {
+	return this.pcs
+}
+
+func TestErr(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	var err = fmt.Errorf("load config: %w", newStackError("file not found"))
+	l.Infof("hello %s", "world", log4go.Err(err))
+	l.Infoln("hello", log4go.NamedErr("cause", errors.New("bad")), "world")
+
+	var records = w.Records()
+	if len(records) != 2 {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+	if records[0].Message != "hello world" || records[1].Message != "hello world\n" {
+		t.Fatalf("日志消息错误: %q %q", records[0].Message, records[1].Message)
+	}
+	if len(records[0].Fields) != 1 || len(records[1].Fields) != 1 {
+		t.Fatalf("日志记录中的字段错误: %v %v", records[0].Fields, records[1].Fields)
+	}
+
+	var detail, ok = records[0].Fields[0].Value.(*log4go.ErrorDetail)
+	if !ok || detail.Message != err.Error() || detail.Type != "*fmt.wrapError" {
+		t.Fatalf("错误信息错误: %+v", detail)
+	}
+	if len(detail.Chain) != 1 || detail.Chain[0].Type != "*log4go_test.stackError" || detail.Chain[0].Message != "file not found" {
+		t.Fatalf("错误链错误: %+v", detail.Chain)
+	}
+	if len(detail.Stack) == 0 || !strings.HasSuffix(detail.Stack[0].Function, "newStackError") {
+		t.Fatalf("错误的调用栈错误: %+v", detail.Stack)
+	}
+
+	var text = string(log4go.NewTextFormatter().Format(&records[0]))
+	var expected = `hello world error="load config: file not found" error.type=*fmt.wrapError error.chain="*log4go_test.stackError: file not found"` + "\nerror.stack:\n"
+	if !strings.Contains(text, expected) || !strings.Contains(text, "newStackError\n\t") {
+		t.Fatalf("文本格式错误: %q", text)
+	}
+	text = string(log4go.NewTextFormatter().Format(&records[1]))
+	if !strings.HasSuffix(text, "hello world cause=bad cause.type=*errors.errorString\n") {
+		t.Fatalf("文本格式错误: %q", text)
+	}
+
+	var obj struct {
+		Msg   string              `json:"msg"`
+		Error *log4go.ErrorDetail `json:"error"`
+	}
+	if err := json.Unmarshal(log4go.NewJSONFormatter().Format(&records[0]), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Msg != "hello world" || obj.Error == nil || obj.Error.Message != err.Error() || len(obj.Error.Chain) != 1 || len(obj.Error.Stack) == 0 {
+		t.Fatalf("JSON 格式错误: %+v", obj)
+	}
+}
@@ -0,0 +1,111 @@
+package log4go
+
+import (
+	"expvar"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExpvarObserver 将统计信息保存在 expvar.Map 中，结构如下：
+//
+//	records        各级别日志的数量
+//	sampled_out    各级别被 Sampler 丢弃的日志数量
+//	writers        以 Writer 名称为键，包含 records、latency_ns、bytes、write_errors、drops、
+//	               rotations、rotate_errors、mails、mail_errors
+type ExpvarObserver struct {
+	mu         sync.Mutex
+	vars       *expvar.Map
+	records    *expvar.Map
+	sampledOut *expvar.Map
+	writers    *expvar.Map
+}
+
+func NewExpvarObserver() *ExpvarObserver {
+	var o = &ExpvarObserver{}
+	o.vars = new(expvar.Map).Init()
+	o.records = new(expvar.Map).Init()
+	o.sampledOut = new(expvar.Map).Init()
+	o.writers = new(expvar.Map).Init()
+	o.vars.Set("records", o.records)
+	o.vars.Set("sampled_out", o.sampledOut)
+	o.vars.Set("writers", o.writers)
+	return o
+}
+
+// PublishExpvar 创建 ExpvarObserver，以 name 发布到 expvar 并设置为当前的 Observer，
+// 与 expvar.Publish 一样，name 重复时会 panic
+func PublishExpvar(name string) *ExpvarObserver {
+	var o = NewExpvarObserver()
+	expvar.Publish(name, o.vars)
+	SetObserver(o)
+	return o
+}
+
+// Map 返回保存统计信息的 expvar.Map
+func (this *ExpvarObserver) Map() *expvar.Map {
+	return this.vars
+}
+
+func (this *ExpvarObserver) writer(name string) *expvar.Map {
+	if m, ok := this.writers.Get(name).(*expvar.Map); ok {
+		return m
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if m, ok := this.writers.Get(name).(*expvar.Map); ok {
+		return m
+	}
+	var m = new(expvar.Map).Init()
+	m.Set("records", new(expvar.Map).Init())
+	this.writers.Set(name, m)
+	return m
+}
+
+func levelKey(level Level) string {
+	return strings.ToLower(level.String())
+}
+
+func (this *ExpvarObserver) ObserveRecord(logger string, level Level, sampled bool) {
+	this.records.Add(levelKey(level), 1)
+	if !sampled {
+		this.sampledOut.Add(levelKey(level), 1)
+	}
+}
+
+func (this *ExpvarObserver) ObserveDispatch(writer string, level Level, latency time.Duration) {
+	var m = this.writer(writer)
+	m.Get("records").(*expvar.Map).Add(levelKey(level), 1)
+	m.Add("latency_ns", int64(latency))
+}
+
+func (this *ExpvarObserver) ObserveWrite(writer string, n int, err error) {
+	var m = this.writer(writer)
+	if n > 0 {
+		m.Add("bytes", int64(n))
+	}
+	if err != nil {
+		m.Add("write_errors", 1)
+	}
+}
+
+func (this *ExpvarObserver) ObserveDrop(writer string, level Level) {
+	this.writer(writer).Add("drops", 1)
+}
+
+func (this *ExpvarObserver) ObserveRotate(writer string, file string, err error) {
+	if err != nil {
+		this.writer(writer).Add("rotate_errors", 1)
+		return
+	}
+	this.writer(writer).Add("rotations", 1)
+}
+
+func (this *ExpvarObserver) ObserveMail(writer string, err error) {
+	if err != nil {
+		this.writer(writer).Add("mail_errors", 1)
+		return
+	}
+	this.writer(writer).Add("mails", 1)
+}
@@ -0,0 +1,97 @@
+package log4go
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field 为附加在日志记录上的键值对
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+func (this Field) String() string {
+	if detail, ok := this.Value.(*ErrorDetail); ok && detail != nil {
+		return detail.text(this.Key)
+	}
+	return this.Key + "=" + fieldText(this.Value)
+}
+
+// splitFields 从日志方法的参数中分离出 Field，其余参数保持原有顺序
+func splitFields(args []interface{}) ([]interface{}, []Field) {
+	var n = 0
+	for _, arg := range args {
+		if _, ok := arg.(Field); ok {
+			n++
+		}
+	}
+	if n == 0 {
+		return args, nil
+	}
+
+	var fields = make([]Field, 0, n)
+	var values = make([]interface{}, 0, len(args)-n)
+	for _, arg := range args {
+		if f, ok := arg.(Field); ok {
+			fields = append(fields, f)
+		} else {
+			values = append(values, arg)
+		}
+	}
+	return values, fields
+}
+
+func sprintf(format string, args []interface{}) (string, []Field) {
+	var values, fields = splitFields(args)
+	return fmt.Sprintf(format, values...), fields
+}
+
+func sprintln(args []interface{}) (string, []Field) {
+	var values, fields = splitFields(args)
+	return fmt.Sprintln(values...), fields
+}
+
+func fieldText(value interface{}) string {
+	var s = fieldString(value)
+	if s == "" || strings.IndexFunc(s, needQuote) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// fieldString 返回字段值的字符串形式，不添加引号
+func fieldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case *ErrorDetail:
+		if v != nil {
+			return v.Message
+		}
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+func needQuote(r rune) bool {
+	return r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r)
+}
+
+// writeTextFields 以 key=value 的形式输出字段，每个字段之前有一个空格
+func writeTextFields(buf *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(f.String())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -55,24 +56,28 @@ func WithLogDir(dir string) FileWriterOption {
 }
 
 type FileWriter struct {
-	level    Level
-	dir      string
-	filename string
-	maxSize  int64
-	maxAge   int64
-	size     int64
-	mu       sync.Mutex
-	cmu      sync.Mutex
-	file     *os.File
-	w        bufio.Writer
+	level     int32
+	dir       string
+	filename  string
+	maxSize   int64
+	maxAge    int64
+	size      int64
+	mu        sync.Mutex
+	cmu       sync.Mutex
+	file      *os.File
+	w         bufio.Writer
+	formatter Formatter
+	observed
 }
 
 func NewFileWriter(level Level, opts ...FileWriterOption) *FileWriter {
 	var fw = &FileWriter{}
-	fw.level = level
+	fw.level = int32(level)
 	fw.dir = kLogDir
 	fw.maxSize = 10 * 1024 * 1024
 	fw.maxAge = 0
+	fw.formatter = NewTextFormatter()
+	fw.setObservedName("file")
 	for _, opt := range opts {
 		opt.Apply(fw)
 	}
@@ -98,6 +103,10 @@ func (this *FileWriter) Write(p []byte) (n int, err error) {
 		return 0, nil
 	}
 
+	defer func() {
+		this.observeWrite(n, err)
+	}()
+
 	this.mu.Lock()
 	defer this.mu.Unlock()
 
@@ -137,11 +146,34 @@ func (this *FileWriter) close() error {
 }
 
 func (this *FileWriter) Level() Level {
-	return this.level
+	return Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *FileWriter) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *FileWriter) SetFormatter(f Formatter) {
+	if f == nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.formatter = f
+}
+
+func (this *FileWriter) Formatter() Formatter {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.formatter
 }
 
 func (this *FileWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
-	fmt.Fprintf(this, "%s%s%s%s %s %s:%d %s", service, instance, prefix, logTime, LevelNames[level], file, line, msg)
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *FileWriter) WriteRecord(r *Record) {
+	this.Write(this.Formatter().Format(r))
 }
 
 func (this *FileWriter) openOrCreate(pLen int64) error {
@@ -185,28 +217,34 @@ func (this *FileWriter) create() error {
 	return nil
 }
 
-func (this *FileWriter) rename() error {
+func (this *FileWriter) rename() (string, error) {
 	_, err := os.Stat(this.filename)
 	if err == nil {
 		var now = time.Now()
 		var newName = path.Join(this.dir, fmt.Sprintf("log_%s_%d.log", now.Format("2006_01_02_15_04_05"), now.Nanosecond()))
 		if err := os.Rename(this.filename, newName); err != nil {
-			return err
+			return "", err
 		}
+		return newName, nil
 	}
-	return err
+	return "", err
 }
 
-func (this *FileWriter) rotate() error {
-	if err := this.close(); err != nil {
+func (this *FileWriter) rotate() (err error) {
+	var newName string
+	defer func() {
+		this.observeRotate(newName, err)
+	}()
+
+	if err = this.close(); err != nil {
 		return err
 	}
 
-	if err := this.rename(); err != nil {
+	if newName, err = this.rename(); err != nil {
 		return err
 	}
 
-	if err := this.create(); err != nil {
+	if err = this.create(); err != nil {
 		return err
 	}
 	this.clean()
@@ -0,0 +1,155 @@
+package log4go
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filter 决定一条日志记录是否交给 Writer 处理
+type Filter interface {
+	Allow(r *Record) bool
+}
+
+type FilterFunc func(r *Record) bool
+
+func (f FilterFunc) Allow(r *Record) bool {
+	return f(r)
+}
+
+func (f FilterFunc) String() string {
+	return "func"
+}
+
+type levelRangeFilter struct {
+	min Level
+	max Level
+}
+
+// LevelRange 只允许级别在 [min, max] 之间的日志记录
+func LevelRange(min, max Level) Filter {
+	return &levelRangeFilter{min: min, max: max}
+}
+
+func (this *levelRangeFilter) Allow(r *Record) bool {
+	return r.Level >= this.min && r.Level <= this.max
+}
+
+func (this *levelRangeFilter) String() string {
+	return fmt.Sprintf("level(%s..%s)", this.min, this.max)
+}
+
+type matchFilter struct {
+	name  string
+	re    *regexp.Regexp
+	field func(r *Record) string
+}
+
+func (this *matchFilter) Allow(r *Record) bool {
+	return this.re.MatchString(this.field(r))
+}
+
+func (this *matchFilter) String() string {
+	return fmt.Sprintf("%s(%s)", this.name, this.re)
+}
+
+func ServiceMatches(re *regexp.Regexp) Filter {
+	return &matchFilter{name: "service", re: re, field: func(r *Record) string { return r.Service }}
+}
+
+func PrefixMatches(re *regexp.Regexp) Filter {
+	return &matchFilter{name: "prefix", re: re, field: func(r *Record) string { return r.Prefix }}
+}
+
+func FileMatches(re *regexp.Regexp) Filter {
+	return &matchFilter{name: "file", re: re, field: func(r *Record) string { return r.File }}
+}
+
+func MessageMatches(re *regexp.Regexp) Filter {
+	return &matchFilter{name: "message", re: re, field: func(r *Record) string { return r.Message }}
+}
+
+type andFilter []Filter
+
+// And 所有 Filter 都允许时才允许，没有 Filter 时允许所有日志记录
+func And(filters ...Filter) Filter {
+	return andFilter(compactFilters(filters))
+}
+
+func (this andFilter) Allow(r *Record) bool {
+	for _, f := range this {
+		if !f.Allow(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (this andFilter) String() string {
+	return joinFilters("and", this)
+}
+
+type orFilter []Filter
+
+// Or 任意一个 Filter 允许时即允许，没有 Filter 时拒绝所有日志记录
+func Or(filters ...Filter) Filter {
+	return orFilter(compactFilters(filters))
+}
+
+func (this orFilter) Allow(r *Record) bool {
+	for _, f := range this {
+		if f.Allow(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (this orFilter) String() string {
+	return joinFilters("or", this)
+}
+
+type notFilter struct {
+	f Filter
+}
+
+// Not 在 f 拒绝时允许，与 And、Or 一样忽略为 nil 的 Filter，f 为 nil 时允许所有日志记录
+func Not(f Filter) Filter {
+	if f == nil {
+		return And()
+	}
+	return &notFilter{f: f}
+}
+
+func (this *notFilter) Allow(r *Record) bool {
+	return !this.f.Allow(r)
+}
+
+func (this *notFilter) String() string {
+	return "not(" + filterString(this.f) + ")"
+}
+
+func compactFilters(filters []Filter) []Filter {
+	var nFilters = make([]Filter, 0, len(filters))
+	for _, f := range filters {
+		if f != nil {
+			nFilters = append(nFilters, f)
+		}
+	}
+	return nFilters
+}
+
+func joinFilters(op string, filters []Filter) string {
+	var names = make([]string, 0, len(filters))
+	for _, f := range filters {
+		names = append(names, filterString(f))
+	}
+	return op + "(" + strings.Join(names, ", ") + ")"
+}
+
+func filterString(f Filter) string {
+	if s, ok := f.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", f)
+}
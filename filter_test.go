@@ -0,0 +1,108 @@
+package log4go_test
+
+import (
+	"github.com/smartwalle/log4go"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+type memoryWriter struct {
+	mu      sync.Mutex
+	level   log4go.Level
+	records []log4go.Record
+	closed  bool
+}
+
+func (this *memoryWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (this *memoryWriter) Close() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.closed = true
+	return nil
+}
+
+func (this *memoryWriter) Closed() bool {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.closed
+}
+
+func (this *memoryWriter) Level() log4go.Level {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.level
+}
+
+func (this *memoryWriter) SetLevel(level log4go.Level) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.level = level
+}
+
+func (this *memoryWriter) WriteMessage(service, instance, prefix, logTime string, level log4go.Level, file string, line int, msg string) {
+	this.WriteRecord(log4go.NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *memoryWriter) WriteRecord(r *log4go.Record) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.records = append(this.records, *r)
+}
+
+func (this *memoryWriter) Records() []log4go.Record {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return append([]log4go.Record(nil), this.records...)
+}
+
+func TestLogger_AddWriterWithFilter(t *testing.T) {
+	var l = log4go.New(log4go.WithPrefix("[payment]"))
+
+	var debug = &memoryWriter{}
+	l.AddWriter("debug", debug, log4go.LevelRange(log4go.LevelTrace, log4go.LevelDebug))
+
+	var mail = &memoryWriter{level: log4go.LevelError}
+	l.AddWriter("mail", mail, log4go.Or(
+		log4go.PrefixMatches(regexp.MustCompile(`^\[payment\]`)),
+		log4go.FileMatches(regexp.MustCompile(`/payment/`)),
+	), log4go.Not(log4go.MessageMatches(regexp.MustCompile(`ignore`))))
+
+	l.Traceln("trace")
+	l.Debugln("debug")
+	l.Infoln("info")
+	l.Warnln("warn")
+	l.WriteMessage(1, log4go.LevelError, "error\n")
+	l.WriteMessage(1, log4go.LevelError, "ignore me\n")
+
+	if records := debug.Records(); len(records) != 2 || records[0].Level != log4go.LevelTrace || records[1].Level != log4go.LevelDebug {
+		t.Fatalf("debug writer 收到的日志记录错误: %v", records)
+	}
+
+	if records := mail.Records(); len(records) != 1 || records[0].Message != "error\n" {
+		t.Fatalf("mail writer 收到的日志记录错误: %v", records)
+	}
+}
+
+func TestFilter_Nil(t *testing.T) {
+	var r = &log4go.Record{Level: log4go.LevelInfo, Message: "hello"}
+	var tests = []struct {
+		name     string
+		filter   log4go.Filter
+		expected bool
+	}{
+		{"and", log4go.And(nil), true},
+		{"or", log4go.Or(nil), false},
+		{"not", log4go.Not(nil), true},
+		{"and not", log4go.And(log4go.Not(nil), nil), true},
+		{"not and", log4go.Not(log4go.And(nil)), false},
+	}
+	for _, test := range tests {
+		if allow := test.filter.Allow(r); allow != test.expected {
+			t.Fatalf("%s: 期望 %v, 实际 %v", test.name, test.expected, allow)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package log4go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	kTimeLayout = "2006/01/02 15:04:05.000000"
+)
+
+// Record 为一条日志记录，由 Logger 生成后交给各个 Writer
+type Record struct {
+	Logger   string
+	Service  string
+	Instance string
+	Prefix   string
+	Time     time.Time
+	Level    Level
+	File     string
+	Line     int
+	Function string
+	Message  string
+	Fields   []Field
+	Stack    []StackFrame
+	// Context 为调用带 context.Context 的日志方法时传入的 ctx，其它情况下为 nil
+	Context context.Context
+}
+
+type Formatter interface {
+	Format(r *Record) []byte
+}
+
+type FormatterFunc func(r *Record) []byte
+
+func (f FormatterFunc) Format(r *Record) []byte {
+	return f(r)
+}
+
+// TextFormatter 输出与早期版本一致的单行文本，Palette 不为 nil 时输出带颜色的内容
+type TextFormatter struct {
+	LevelStyle LevelStyle
+	TimeLayout string
+	Palette    *Palette
+	// ShowFunction 为 true 时在文件及行号之后输出函数名
+	ShowFunction bool
+}
+
+func NewTextFormatter() *TextFormatter {
+	return &TextFormatter{LevelStyle: LevelStyleShort, TimeLayout: kTimeLayout}
+}
+
+func (this *TextFormatter) Format(r *Record) []byte {
+	var layout = this.TimeLayout
+	if layout == "" {
+		layout = kTimeLayout
+	}
+
+	var levelName = this.LevelStyle.Format(r.Level)
+	var position = r.File + ":" + strconv.Itoa(r.Line)
+	if this.ShowFunction && r.Function != "" {
+		position += " " + r.Function
+	}
+	var msg = r.Message
+	if this.Palette != nil {
+		levelName = this.Palette.Level(r.Level).Wrap(levelName)
+		position = this.Palette.File.Wrap(position)
+		// 换行符留在颜色控制码之外，避免影响下一行
+		var body = strings.TrimRight(msg, "\n")
+		msg = this.Palette.Message.Wrap(body) + msg[len(body):]
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(r.Service)
+	buf.WriteString(r.Instance)
+	buf.WriteString(r.Prefix)
+	buf.WriteString(r.Time.Format(layout))
+	buf.WriteByte(' ')
+	buf.WriteString(levelName)
+	buf.WriteByte(' ')
+	if r.Logger != "" {
+		buf.WriteString(r.Logger)
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(position)
+	buf.WriteByte(' ')
+	if len(r.Fields) == 0 {
+		buf.WriteString(msg)
+	} else {
+		// 字段输出在消息之后、换行符之前
+		var body = strings.TrimRight(msg, "\n")
+		buf.WriteString(body)
+		writeTextFields(&buf, r.Fields)
+		buf.WriteString(msg[len(body):])
+	}
+
+	if len(r.Stack) > 0 {
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		writeTextStack(&buf, r.Stack)
+	}
+	writeTextErrorStacks(&buf, r.Fields)
+	return buf.Bytes()
+}
+
+// writeTextErrorStacks 输出 Err 字段中携带的调用栈
+func writeTextErrorStacks(buf *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		var detail, ok = f.Value.(*ErrorDetail)
+		if !ok || detail == nil || len(detail.Stack) == 0 {
+			continue
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(f.Key)
+		buf.WriteString(".stack:\n")
+		writeTextStack(buf, detail.Stack)
+	}
+}
+
+// writeTextStack 以与 runtime/debug.Stack 相同的格式输出调用栈
+func writeTextStack(buf *bytes.Buffer, stack []StackFrame) {
+	for _, frame := range stack {
+		buf.WriteString(frame.Function)
+		buf.WriteString("\n\t")
+		buf.WriteString(frame.File)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(frame.Line))
+		buf.WriteByte('\n')
+	}
+}
+
+// JSONFormatter 每条日志输出为一行 JSON
+type JSONFormatter struct {
+	LevelStyle LevelStyle
+	TimeLayout string
+}
+
+func NewJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{LevelStyle: LevelStyleLower, TimeLayout: time.RFC3339Nano}
+}
+
+var jsonReservedKeys = map[string]struct{}{
+	"time":     {},
+	"level":    {},
+	"logger":   {},
+	"service":  {},
+	"instance": {},
+	"prefix":   {},
+	"file":     {},
+	"line":     {},
+	"func":     {},
+	"msg":      {},
+	"stack":    {},
+}
+
+func (this *JSONFormatter) Format(r *Record) []byte {
+	var layout = this.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	var obj = &jsonObject{}
+	obj.add("time", r.Time.Format(layout))
+	obj.add("level", this.LevelStyle.Format(r.Level))
+	obj.addString("logger", r.Logger)
+	obj.addString("service", r.Service)
+	obj.addString("instance", r.Instance)
+	obj.addString("prefix", r.Prefix)
+	obj.add("file", r.File)
+	obj.add("line", r.Line)
+	obj.addString("func", r.Function)
+	obj.add("msg", strings.TrimRight(r.Message, "\n"))
+	if len(r.Stack) > 0 {
+		obj.add("stack", r.Stack)
+	}
+	for _, f := range r.Fields {
+		var key = f.Key
+		// 避免与固定的键冲突
+		if _, ok := jsonReservedKeys[key]; ok {
+			key = "fields." + key
+		}
+		obj.add(key, jsonValue(f.Value))
+	}
+	return obj.bytes()
+}
+
+// jsonObject 按添加的顺序输出 JSON 对象
+type jsonObject struct {
+	buf bytes.Buffer
+}
+
+func (this *jsonObject) addString(key, value string) {
+	if value != "" {
+		this.add(key, value)
+	}
+}
+
+func (this *jsonObject) add(key string, value interface{}) {
+	if this.buf.Len() == 0 {
+		this.buf.WriteByte('{')
+	} else {
+		this.buf.WriteByte(',')
+	}
+	this.buf.Write(marshalJSON(key))
+	this.buf.WriteByte(':')
+	this.buf.Write(marshalJSON(value))
+}
+
+func (this *jsonObject) bytes() []byte {
+	if this.buf.Len() == 0 {
+		this.buf.WriteByte('{')
+	}
+	this.buf.WriteString("}\n")
+	return this.buf.Bytes()
+}
+
+func marshalJSON(v interface{}) []byte {
+	var buf bytes.Buffer
+	var encoder = json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		buf.Reset()
+		encoder.Encode(fmt.Sprint(v))
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+// jsonValue 将不能直接序列化为 JSON 的值转换为字符串
+func jsonValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Marshaler:
+		return value
+	case error:
+		return value.Error()
+	case fmt.Stringer:
+		return value.String()
+	}
+	return v
+}
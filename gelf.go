@@ -0,0 +1,341 @@
+package log4go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type GELFCompression int
+
+const (
+	GELFCompressionGzip GELFCompression = iota
+	GELFCompressionZlib
+	GELFCompressionNone
+)
+
+// ParseGELFCompression 解析压缩方式，可以为 gzip、zlib 或者 none
+func ParseGELFCompression(s string) (GELFCompression, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "gzip":
+		return GELFCompressionGzip, nil
+	case "zlib":
+		return GELFCompressionZlib, nil
+	case "none":
+		return GELFCompressionNone, nil
+	}
+	return GELFCompressionGzip, fmt.Errorf("未知的 GELF 压缩方式 %q", s)
+}
+
+const (
+	kGELFVersion     = "1.1"
+	kGELFChunkSize   = 1420
+	kGELFMaxChunks   = 128
+	kGELFChunkHeader = 12
+	kGELFDialTimeout = 5 * time.Second
+	kGELFMinBackoff  = time.Second
+	kGELFMaxBackoff  = 30 * time.Second
+)
+
+var (
+	kGELFChunkMagic = []byte{0x1e, 0x0f}
+	kGELFInvalidKey = regexp.MustCompile(`[^\w.\-]`)
+	errGELFTooLarge = errors.New("GELF 消息超出最大分块数量")
+
+	kGELFReservedKeys = map[string]struct{}{
+		"_id":      {},
+		"_service": {},
+		"_logger":  {},
+		"_prefix":  {},
+		"_file":    {},
+		"_line":    {},
+		"_func":    {},
+	}
+)
+
+type GELFWriterOption interface {
+	Apply(*GELFWriter)
+}
+
+type gwOptionFunc func(*GELFWriter)
+
+func (f gwOptionFunc) Apply(w *GELFWriter) {
+	f(w)
+}
+
+// WithGELFCompression 设置 UDP 消息的压缩方式，默认为 gzip，TCP 不压缩
+func WithGELFCompression(c GELFCompression) GELFWriterOption {
+	return gwOptionFunc(func(w *GELFWriter) {
+		w.compression = c
+	})
+}
+
+// WithGELFChunkSize 设置 UDP 分块的最大字节数（包含 12 字节的分块头），默认为 1420
+func WithGELFChunkSize(n int) GELFWriterOption {
+	return gwOptionFunc(func(w *GELFWriter) {
+		if n > kGELFChunkHeader {
+			w.chunkSize = n
+		}
+	})
+}
+
+// WithGELFHost 设置 host，默认使用 Record.Instance，为空时使用当前主机的名称
+func WithGELFHost(host string) GELFWriterOption {
+	return gwOptionFunc(func(w *GELFWriter) {
+		w.host = host
+	})
+}
+
+// WithGELFBackoff 设置连接失败之后的等待时间，从 min 开始每次失败后加倍，最大为 max，
+// 等待期间写入的日志直接返回错误，不再尝试连接
+func WithGELFBackoff(min, max time.Duration) GELFWriterOption {
+	return gwOptionFunc(func(w *GELFWriter) {
+		if min > 0 {
+			w.conn.backoff.min = min
+		}
+		if max >= w.conn.backoff.min {
+			w.conn.backoff.max = max
+		}
+	})
+}
+
+// GELFWriter 以 GELF 1.1 格式将日志发送到 Graylog，network 可以为 udp 或者 tcp。
+// UDP 消息会被压缩，超过分块大小时分块发送；TCP 消息不压缩，以 \0 结尾。
+// Record.Instance 对应 host，Service、File、Line 等信息以 _service、_file、_line 等附加字段发送。
+// 连接失败之后在等待时间内不再尝试连接，参考 WithGELFBackoff
+type GELFWriter struct {
+	level       int32
+	mu          sync.Mutex
+	network     string
+	addr        string
+	compression GELFCompression
+	chunkSize   int
+	host        string
+	hostname    string
+	conn        retryConn
+	observed
+}
+
+func NewGELFWriter(level Level, network, addr string, opts ...GELFWriterOption) *GELFWriter {
+	var gw = &GELFWriter{}
+	gw.level = int32(level)
+	gw.network = network
+	gw.addr = addr
+	gw.compression = GELFCompressionGzip
+	gw.chunkSize = kGELFChunkSize
+	gw.hostname, _ = os.Hostname()
+	gw.conn.backoff.min = kGELFMinBackoff
+	gw.conn.backoff.max = kGELFMaxBackoff
+	gw.setObservedName("gelf")
+	for _, opt := range opts {
+		opt.Apply(gw)
+	}
+	return gw
+}
+
+// Write 以 Info 级别将 p 作为消息发送
+func (this *GELFWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var r = &Record{Time: time.Now(), Level: LevelInfo, Message: string(p), Line: -1}
+	if err = this.send(this.encode(r)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (this *GELFWriter) Close() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.conn.close()
+}
+
+func (this *GELFWriter) Level() Level {
+	return Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *GELFWriter) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *GELFWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *GELFWriter) WriteRecord(r *Record) {
+	this.send(this.encode(r))
+}
+
+func (this *GELFWriter) encode(r *Record) []byte {
+	var host = this.host
+	if host == "" {
+		host = r.Instance
+	}
+	if host == "" {
+		host = this.hostname
+	}
+
+	var message = strings.TrimRight(r.Message, "\n")
+	var short = message
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short = short[:i]
+	}
+	var full = message
+	if len(r.Stack) > 0 {
+		var buf bytes.Buffer
+		buf.WriteString(message)
+		buf.WriteByte('\n')
+		writeTextStack(&buf, r.Stack)
+		full = strings.TrimRight(buf.String(), "\n")
+	}
+
+	var obj = &jsonObject{}
+	obj.add("version", kGELFVersion)
+	obj.add("host", host)
+	obj.add("short_message", short)
+	if full != short {
+		obj.add("full_message", full)
+	}
+	obj.add("timestamp", float64(r.Time.UnixNano())/float64(time.Second))
+	obj.add("level", r.Level.SyslogSeverity())
+	obj.addString("_service", r.Service)
+	obj.addString("_logger", r.Logger)
+	obj.addString("_prefix", r.Prefix)
+	obj.addString("_file", r.File)
+	if r.Line >= 0 {
+		obj.add("_line", r.Line)
+	}
+	obj.addString("_func", r.Function)
+	for _, f := range r.Fields {
+		obj.add(gelfKey(f.Key), gelfValue(f.Value))
+	}
+	return bytes.TrimRight(obj.bytes(), "\n")
+}
+
+// gelfKey 附加字段的名称只能包含字母、数字、_、. 和 -，并且以 _ 开头，_id 为保留的名称
+// gelfKey 返回字段对应的附加字段名称，_id 为 GELF 保留的名称，_service、_file 等为 Record 本身的信息使用的名称，
+// 字段名称与其相同时改为 _field_id、_field_service 等，避免 JSON 中出现重复的键
+func gelfKey(key string) string {
+	key = "_" + kGELFInvalidKey.ReplaceAllString(key, "_")
+	if _, ok := kGELFReservedKeys[key]; ok {
+		key = "_field" + key
+	}
+	return key
+}
+
+// gelfValue 附加字段的值只能为字符串或者数字
+func gelfValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	}
+	return fieldString(value)
+}
+
+func (this *GELFWriter) send(msg []byte) (err error) {
+	var n int
+	defer func() {
+		this.observeWrite(n, err)
+	}()
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	var stream = isStreamNetwork(this.network)
+	if !stream {
+		if msg, err = this.compress(msg); err != nil {
+			return err
+		}
+		if this.chunkCount(msg) > kGELFMaxChunks {
+			return errGELFTooLarge
+		}
+	}
+
+	n, err = this.conn.write(func() (net.Conn, error) {
+		return net.DialTimeout(this.network, this.addr, kGELFDialTimeout)
+	}, func(conn net.Conn) (int, error) {
+		if stream {
+			return conn.Write(append(msg, 0))
+		}
+		return this.writeChunks(conn, msg)
+	})
+	return err
+}
+
+func (this *GELFWriter) compress(msg []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch this.compression {
+	case GELFCompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case GELFCompressionZlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		return msg, nil
+	}
+	if _, err := w.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// chunkCount 返回 UDP 消息需要的分块数量
+func (this *GELFWriter) chunkCount(msg []byte) int {
+	if len(msg) <= this.chunkSize {
+		return 1
+	}
+	var size = this.chunkSize - kGELFChunkHeader
+	return (len(msg) + size - 1) / size
+}
+
+// writeChunks 消息超过分块大小时，按 GELF 的格式分块发送，每块之前为 12 字节的分块头：
+// 0x1e 0x0f、8 字节的消息 id、1 字节的序号及 1 字节的分块数量
+func (this *GELFWriter) writeChunks(conn net.Conn, msg []byte) (int, error) {
+	if len(msg) <= this.chunkSize {
+		return conn.Write(msg)
+	}
+
+	var size = this.chunkSize - kGELFChunkHeader
+	var count = this.chunkCount(msg)
+
+	var id = make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return 0, err
+	}
+
+	var total = 0
+	var chunk = make([]byte, 0, this.chunkSize)
+	for i := 0; i < count; i++ {
+		var end = (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk = append(chunk[:0], kGELFChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:end]...)
+		n, err := conn.Write(chunk)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,205 @@
+package log4go_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFWriter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var gw = log4go.NewGELFWriter(log4go.LevelTrace, "udp", conn.LocalAddr().String(), log4go.WithGELFChunkSize(64))
+	defer gw.Close()
+
+	var l = log4go.New(log4go.WithService("order"), log4go.WithInstance("node-1"))
+	l.AddWriter("gelf", gw)
+	l.Warnln("hello\nworld", log4go.Any("id", 10), log4go.Any("user name", "bob"), log4go.Any("service", "payment"), log4go.Any("line", 1))
+
+	// 按序号重新组合分块
+	var chunks = map[byte][]byte{}
+	var count byte
+	var buf = make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for count == 0 || len(chunks) < int(count) {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 64 || buf[0] != 0x1e || buf[1] != 0x0f {
+			t.Fatalf("分块格式错误: %x", buf[:n])
+		}
+		count = buf[11]
+		chunks[buf[10]] = append([]byte(nil), buf[12:n]...)
+	}
+	if count < 2 {
+		t.Fatalf("消息应该被分块发送: %d", count)
+	}
+
+	var data []byte
+	for i := byte(0); i < count; i++ {
+		data = append(data, chunks[i]...)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = ioutil.ReadAll(zr); err != nil {
+		t.Fatal(err)
+	}
+
+	var obj map[string]interface{}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["version"] != "1.1" || obj["host"] != "node-1" || obj["_service"] != "order" || obj["level"] != float64(4) {
+		t.Fatalf("GELF 消息错误: %s", data)
+	}
+	if obj["short_message"] != "hello" || obj["full_message"] != "hello\nworld" || obj["_field_id"] != float64(10) || obj["_user_name"] != "bob" {
+		t.Fatalf("GELF 消息错误: %s", data)
+	}
+	if !strings.HasSuffix(obj["_file"].(string), "gelf_test.go") || obj["_line"].(float64) <= 1 {
+		t.Fatalf("GELF 消息错误: %s", data)
+	}
+	// 与 Record 本身的信息同名的字段改为 _field_ 开头，不会出现重复的键
+	if obj["_field_service"] != "payment" || obj["_field_line"] != float64(1) || bytes.Count(data, []byte(`"_service"`)) != 1 {
+		t.Fatalf("GELF 消息错误: %s", data)
+	}
+}
+
+func TestGELFWriter_Zlib(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var gw = log4go.NewGELFWriter(log4go.LevelTrace, "udp", conn.LocalAddr().String(), log4go.WithGELFCompression(log4go.GELFCompressionZlib), log4go.WithGELFHost("host"))
+	defer gw.Close()
+	gw.Write([]byte("hello\n"))
+
+	var buf = make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(buf[:n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var obj map[string]interface{}
+	if err = json.Unmarshal(data, &obj); err != nil || obj["short_message"] != "hello" || obj["host"] != "host" || obj["level"] != float64(6) {
+		t.Fatalf("GELF 消息错误: %s %v", data, err)
+	}
+}
+
+func TestGELFWriter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var messages = make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var r = bufio.NewReader(conn)
+		for {
+			msg, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			messages <- strings.TrimSuffix(msg, "\x00")
+		}
+	}()
+
+	var gw = log4go.NewGELFWriter(log4go.LevelTrace, "tcp", ln.Addr().String())
+	defer gw.Close()
+
+	var l = log4go.New()
+	l.AddWriter("gelf", gw)
+	l.Infoln("first")
+	l.Infoln("second")
+
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case msg := <-messages:
+			var obj map[string]interface{}
+			if err = json.Unmarshal([]byte(msg), &obj); err != nil || obj["short_message"] != expected {
+				t.Fatalf("GELF 消息错误: %s %v", msg, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("没有收到 GELF 消息")
+		}
+	}
+}
+
+func TestGELFWriter_Backoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addr = ln.Addr().String()
+	ln.Close()
+
+	var gw = log4go.NewGELFWriter(log4go.LevelTrace, "tcp", addr, log4go.WithGELFBackoff(200*time.Millisecond, time.Second))
+	defer gw.Close()
+
+	if _, err = gw.Write([]byte("hello")); err == nil {
+		t.Fatal("连接失败时应该返回错误")
+	}
+
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	// 等待时间内不再尝试连接
+	if _, err = gw.Write([]byte("hello")); err == nil {
+		t.Fatal("等待时间内应该直接返回错误")
+	}
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(50 * time.Millisecond))
+	if conn, err := ln.Accept(); err == nil {
+		conn.Close()
+		t.Fatal("等待时间内不应该重新连接")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if _, err = gw.Write([]byte("hello")); err != nil {
+		t.Fatalf("等待时间之后应该重新连接: %v", err)
+	}
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	msg, err := bufio.NewReader(conn).ReadString(0)
+	var obj map[string]interface{}
+	if err != nil || json.Unmarshal([]byte(strings.TrimSuffix(msg, "\x00")), &obj) != nil || obj["short_message"] != "hello" {
+		t.Fatalf("GELF 消息错误: %q %v", msg, err)
+	}
+}
@@ -1,8 +1,9 @@
 module github.com/smartwalle/log4go
 
-go 1.12
+go 1.13
 
 require (
 	github.com/mattn/go-isatty v0.0.7
 	github.com/smartwalle/mail4go v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,166 @@
+package log4go
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	kLevelNotSet      = math.MinInt32
+	kLevelMax         = math.MaxInt32
+	kStackLimitNotSet = math.MinInt32
+	kStackModeNotSet  = StackMode(-1)
+	kLoggerSeparator  = "/"
+)
+
+// toggle 为可以沿用父 Logger 设置的开关
+type toggle int8
+
+const (
+	toggleNotSet toggle = iota
+	toggleOn
+	toggleOff
+)
+
+func (this *logger) Name() string {
+	return this.name
+}
+
+func (this *logger) GetLogger(name string) Logger {
+	var l = this
+	for _, seg := range splitLoggerName(name) {
+		l = l.child(seg)
+	}
+	return l
+}
+
+func splitLoggerName(name string) []string {
+	var segs []string
+	for _, seg := range strings.Split(name, kLoggerSeparator) {
+		if seg = strings.TrimSpace(seg); seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+func normalizeLoggerName(name string) string {
+	return strings.Join(splitLoggerName(name), kLoggerSeparator)
+}
+
+// walk 依次访问当前 Logger 及其所有子孙
+func (this *logger) walk(fn func(l *logger)) {
+	fn(this)
+
+	this.mu.Lock()
+	var children = make([]*logger, 0, len(this.children))
+	for _, c := range this.children {
+		children = append(children, c)
+	}
+	this.mu.Unlock()
+
+	for _, c := range children {
+		c.walk(fn)
+	}
+}
+
+type namedEntry struct {
+	name string
+	*writerEntry
+}
+
+func (this *logger) namedEntries() []namedEntry {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	var entries = make([]namedEntry, 0, len(this.writers))
+	for name, entry := range this.writers {
+		entries = append(entries, namedEntry{name: name, writerEntry: entry})
+	}
+	return entries
+}
+
+// setWriters 替换全部 Writer，返回被替换掉的 Writer
+func (this *logger) setWriters(writers map[string]*writerEntry) []*writerEntry {
+	if writers == nil {
+		writers = make(map[string]*writerEntry)
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	var old = make([]*writerEntry, 0, len(this.writers))
+	for _, entry := range this.writers {
+		old = append(old, entry)
+	}
+	this.writers = writers
+	return old
+}
+
+// lookup 返回已经存在的命名 Logger，与 GetLogger 不同，不会创建新的 Logger
+func (this *logger) lookup(name string) (*logger, bool) {
+	var l = this
+	for _, seg := range splitLoggerName(name) {
+		l.mu.Lock()
+		var c, ok = l.children[seg]
+		l.mu.Unlock()
+		if !ok {
+			return nil, false
+		}
+		l = c
+	}
+	return l, true
+}
+
+func (this *logger) child(seg string) *logger {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if c, ok := this.children[seg]; ok {
+		return c
+	}
+
+	var c = this.newChild()
+	c.name = seg
+	if this.name != "" {
+		c.name = this.name + kLoggerSeparator + seg
+	}
+
+	if this.children == nil {
+		this.children = make(map[string]*logger)
+	}
+	this.children[seg] = c
+	return c
+}
+
+func (this *logger) newChild() *logger {
+	var c = &logger{}
+	c.parent = this
+	c.tree = this.tree
+	c.name = this.name
+	c.writers = make(map[string]*writerEntry)
+	// 未设置的级别、调用栈及路径等设置在使用时沿用最近祖先的设置，祖先之后的修改对子 Logger 同样生效
+	c.level = kLevelNotSet
+	c.stackLevel = kLevelNotSet
+	c.printStack = toggleNotSet
+	c.stackMode = kStackModeNotSet
+	c.stackLimit = kStackLimitNotSet
+	c.printPath = toggleNotSet
+	return c
+}
+
+func (this *logger) With(opts ...Option) Logger {
+	this.mu.Lock()
+	var c = this.newChild()
+	c.callerSkip = this.callerSkip
+	this.mu.Unlock()
+
+	for _, opt := range opts {
+		opt.Apply(c)
+	}
+	return c
+}
+
+// GetLogger 返回 SharedLogger 下的命名 Logger，如 GetLogger("app/db")，
+// 相同名称返回同一个 Logger
+func GetLogger(name string) Logger {
+	return sharedLogger.GetLogger(name)
+}
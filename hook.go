@@ -0,0 +1,79 @@
+package log4go
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// Hook 在日志记录交给 Writer 处理之前调用，可以修改日志记录，如添加字段、改写消息
+type Hook interface {
+	Fire(r *Record)
+}
+
+type HookFunc func(r *Record)
+
+func (f HookFunc) Fire(r *Record) {
+	f(r)
+}
+
+type hookEntry struct {
+	levels map[Level]struct{}
+	hook   Hook
+}
+
+func newHookEntry(levels []Level, hook Hook) *hookEntry {
+	var entry = &hookEntry{hook: hook}
+	if len(levels) > 0 {
+		entry.levels = make(map[Level]struct{}, len(levels))
+		for _, level := range levels {
+			entry.levels[level] = struct{}{}
+		}
+	}
+	return entry
+}
+
+func (this *hookEntry) fire(r *Record) {
+	if this.levels != nil {
+		if _, ok := this.levels[r.Level]; !ok {
+			return
+		}
+	}
+	this.hook.Fire(r)
+}
+
+// HostnameHook 添加键为 hostname 的字段，值为当前主机的名称
+func HostnameHook() Hook {
+	var hostname, _ = os.Hostname()
+	return HookFunc(func(r *Record) {
+		r.Fields = append(r.Fields, Any("hostname", hostname))
+	})
+}
+
+// PidHook 添加键为 pid 的字段，值为当前进程的 id
+func PidHook() Hook {
+	var pid = os.Getpid()
+	return HookFunc(func(r *Record) {
+		r.Fields = append(r.Fields, Any("pid", pid))
+	})
+}
+
+// GoroutineHook 添加键为 goroutine 的字段，值为写日志的 goroutine 的 id
+func GoroutineHook() Hook {
+	return HookFunc(func(r *Record) {
+		r.Fields = append(r.Fields, Any("goroutine", goroutineId()))
+	})
+}
+
+// goroutineId 从调用栈的第一行 "goroutine 1 [running]:" 中获取当前 goroutine 的 id
+func goroutineId() uint64 {
+	var buf [64]byte
+	var b = buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	var id, _ = strconv.ParseUint(string(b), 10, 64)
+	return id
+}
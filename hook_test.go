@@ -0,0 +1,39 @@
+package log4go_test
+
+import (
+	"github.com/smartwalle/log4go"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger_AddHook(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+	l.AddHook(nil, log4go.PidHook())
+	l.AddHook([]log4go.Level{log4go.LevelWarning}, log4go.HookFunc(func(r *log4go.Record) {
+		r.Message = strings.ToUpper(r.Message)
+	}))
+
+	var c = l.GetLogger("child")
+	c.AddHook(nil, log4go.GoroutineHook())
+
+	l.Infoln("hello")
+	c.Warnln("hello")
+
+	var records = w.Records()
+	if len(records) != 2 {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+	if records[0].Message != "hello\n" || len(records[0].Fields) != 1 || records[0].Fields[0].Key != "pid" || records[0].Fields[0].Value != os.Getpid() {
+		t.Fatalf("日志记录错误: %q %v", records[0].Message, records[0].Fields)
+	}
+	// 父 Logger 的 Hook 先于子 Logger 的 Hook 调用
+	if records[1].Message != "HELLO\n" || len(records[1].Fields) != 2 || records[1].Fields[0].Key != "pid" || records[1].Fields[1].Key != "goroutine" {
+		t.Fatalf("日志记录错误: %q %v", records[1].Message, records[1].Fields)
+	}
+	if id, ok := records[1].Fields[1].Value.(uint64); !ok || id == 0 {
+		t.Fatalf("goroutine id 错误: %v", records[1].Fields[1].Value)
+	}
+}
@@ -0,0 +1,409 @@
+package log4go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	kHTTPQueueSize     = 4096
+	kHTTPBatchSize     = 100
+	kHTTPFlushInterval = time.Second
+	kHTTPMaxRetries    = 3
+	kHTTPMinBackoff    = 500 * time.Millisecond
+	kHTTPMaxBackoff    = 30 * time.Second
+	kHTTPTimeout       = 10 * time.Second
+)
+
+type HTTPBatchFormat int
+
+const (
+	HTTPBatchNDJSON    HTTPBatchFormat = iota // 每条日志一行
+	HTTPBatchJSONArray                        // 所有日志组成一个 JSON 数组，Formatter 的输出需要为 JSON
+)
+
+// ParseHTTPBatchFormat 解析批量发送的格式，可以为 ndjson 或者 array
+func ParseHTTPBatchFormat(s string) (HTTPBatchFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "ndjson":
+		return HTTPBatchNDJSON, nil
+	case "array", "json":
+		return HTTPBatchJSONArray, nil
+	}
+	return HTTPBatchNDJSON, fmt.Errorf("未知的批量格式 %q", s)
+}
+
+type HTTPWriterOption interface {
+	Apply(*HTTPWriter)
+}
+
+type hwOptionFunc func(*HTTPWriter)
+
+func (f hwOptionFunc) Apply(w *HTTPWriter) {
+	f(w)
+}
+
+func WithHTTPHeader(key, value string) HTTPWriterOption {
+	return hwOptionFunc(func(w *HTTPWriter) {
+		w.header.Add(key, value)
+	})
+}
+
+func WithHTTPBatchFormat(format HTTPBatchFormat) HTTPWriterOption {
+	return hwOptionFunc(func(w *HTTPWriter) {
+		w.batchFormat = format
+	})
+}
+
+// WithHTTPGzip 使用 gzip 压缩请求内容
+func WithHTTPGzip() HTTPWriterOption {
+	return hwOptionFunc(func(w *HTTPWriter) {
+		w.gzip = true
+	})
+}
+
+// WithHTTPBatch 设置每次最多发送 size 条日志，缓存中的日志最多等待 interval 之后发送
+func WithHTTPBatch(size int, interval time.Duration) HTTPWriterOption {
+	return hwOptionFunc(func(w *HTTPWriter) {
+		if size > 0 {
+			w.batchSize = size
+		}
+		if interval > 0 {
+			w.flushInterval = interval
+		}
+	})
+}
+
+// WithHTTPRetry 设置请求失败（网络错误、5xx 或者 429）时的最大重试次数，
+// 重试的等待时间从 min 开始每次加倍，最大为 max，响应中有 Retry-After 时优先使用
+func WithHTTPRetry(retries int, min, max time.Duration) HTTPWriterOption {
+	return hwOptionFunc(func(w *HTTPWriter) {
+		if retries >= 0 {
+			w.maxRetries = retries
+		}
+		if min > 0 {
+			w.minBackoff = min
+		}
+		if max >= w.minBackoff {
+			w.maxBackoff = max
+		}
+	})
+}
+
+// WithHTTPQueueSize 设置等待发送的日志的最大数量，超出时丢弃新的日志
+func WithHTTPQueueSize(n int) HTTPWriterOption {
+	return hwOptionFunc(func(w *HTTPWriter) {
+		if n > 0 {
+			w.queueSize = n
+		}
+	})
+}
+
+func WithHTTPClient(c *http.Client) HTTPWriterOption {
+	return hwOptionFunc(func(w *HTTPWriter) {
+		if c != nil {
+			w.client = c
+		}
+	})
+}
+
+// HTTPWriter 将日志分批通过 POST 请求发送到 url，默认每条日志为一行 JSON。
+// 日志先放入有界的队列中由后台的 goroutine 发送，队列已满、重试失败或者服务端拒绝的日志会被丢弃，
+// 丢弃的数量可以通过 Dropped 获取
+type HTTPWriter struct {
+	dropped       uint64
+	level         int32
+	closed        int32
+	url           string
+	header        http.Header
+	client        *http.Client
+	batchFormat   HTTPBatchFormat
+	gzip          bool
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	queueSize     int
+	mu            sync.Mutex
+	formatter     Formatter
+	queue         chan queuedMessage
+	stop          chan struct{}
+	done          chan struct{}
+	stopOnce      sync.Once
+	observed
+}
+
+func NewHTTPWriter(level Level, url string, opts ...HTTPWriterOption) *HTTPWriter {
+	var hw = &HTTPWriter{}
+	hw.level = int32(level)
+	hw.url = url
+	hw.header = make(http.Header)
+	hw.client = &http.Client{Timeout: kHTTPTimeout}
+	hw.batchSize = kHTTPBatchSize
+	hw.flushInterval = kHTTPFlushInterval
+	hw.maxRetries = kHTTPMaxRetries
+	hw.minBackoff = kHTTPMinBackoff
+	hw.maxBackoff = kHTTPMaxBackoff
+	hw.queueSize = kHTTPQueueSize
+	hw.formatter = NewJSONFormatter()
+	hw.setObservedName("http")
+	for _, opt := range opts {
+		opt.Apply(hw)
+	}
+
+	hw.queue = make(chan queuedMessage, hw.queueSize)
+	hw.stop = make(chan struct{})
+	hw.done = make(chan struct{})
+
+	go hw.run()
+	return hw
+}
+
+// Write 将 p 作为一条日志放入队列，不会等待发送完成
+func (this *HTTPWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var data = make([]byte, len(p))
+	copy(data, p)
+	this.enqueue(queuedMessage{level: this.Level(), data: data})
+	return len(p), nil
+}
+
+// Close 发送队列中剩余的日志之后停止后台的 goroutine，此时请求失败不再重试
+func (this *HTTPWriter) Close() error {
+	this.stopOnce.Do(func() {
+		atomic.StoreInt32(&this.closed, 1)
+		close(this.stop)
+	})
+	<-this.done
+	return nil
+}
+
+func (this *HTTPWriter) Level() Level {
+	return Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *HTTPWriter) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *HTTPWriter) SetFormatter(f Formatter) {
+	if f == nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.formatter = f
+}
+
+func (this *HTTPWriter) Formatter() Formatter {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.formatter
+}
+
+func (this *HTTPWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *HTTPWriter) WriteRecord(r *Record) {
+	this.enqueue(queuedMessage{level: r.Level, data: this.Formatter().Format(r)})
+}
+
+// Dropped 返回因为队列已满或者发送失败而丢弃的日志数量
+func (this *HTTPWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *HTTPWriter) enqueue(m queuedMessage) {
+	if atomic.LoadInt32(&this.closed) == 0 {
+		select {
+		case this.queue <- m:
+			return
+		default:
+		}
+	}
+	this.drop([]queuedMessage{m})
+}
+
+func (this *HTTPWriter) drop(batch []queuedMessage) {
+	atomic.AddUint64(&this.dropped, uint64(len(batch)))
+	for _, m := range batch {
+		this.observeDrop(m.level)
+	}
+}
+
+func (this *HTTPWriter) run() {
+	defer close(this.done)
+
+	var ticker = time.NewTicker(this.flushInterval)
+	defer ticker.Stop()
+
+	var batch = make([]queuedMessage, 0, this.batchSize)
+	var flush = func() {
+		if len(batch) > 0 {
+			this.send(batch)
+			batch = make([]queuedMessage, 0, this.batchSize)
+		}
+	}
+
+	for {
+		select {
+		case m := <-this.queue:
+			if batch = append(batch, m); len(batch) >= this.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-this.stop:
+			// 发送队列中剩余的日志
+			for {
+				select {
+				case m := <-this.queue:
+					if batch = append(batch, m); len(batch) >= this.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send 发送一批日志，可以重试的错误按退避时间重试，失败时丢弃这批日志
+func (this *HTTPWriter) send(batch []queuedMessage) {
+	body, err := this.encode(batch)
+	if err != nil {
+		this.observeWrite(0, err)
+		this.drop(batch)
+		return
+	}
+
+	var backoff = this.minBackoff
+	for i := 0; ; i++ {
+		retry, wait, err := this.post(body)
+		if err == nil {
+			return
+		}
+		if !retry || i >= this.maxRetries {
+			break
+		}
+		if wait < backoff {
+			wait = backoff
+		}
+		if wait > this.maxBackoff {
+			wait = this.maxBackoff
+		}
+		if !this.sleep(wait) {
+			break
+		}
+		if backoff *= 2; backoff > this.maxBackoff {
+			backoff = this.maxBackoff
+		}
+	}
+	this.drop(batch)
+}
+
+func (this *HTTPWriter) sleep(d time.Duration) bool {
+	var timer = time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-this.stop:
+		return false
+	}
+}
+
+func (this *HTTPWriter) encode(batch []queuedMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw *gzip.Writer
+	if this.gzip {
+		zw = gzip.NewWriter(&buf)
+		w = zw
+	}
+
+	if this.batchFormat == HTTPBatchJSONArray {
+		w.Write([]byte{'['})
+	}
+	for i, m := range batch {
+		var data = bytes.TrimRight(m.data, "\n")
+		if this.batchFormat == HTTPBatchJSONArray {
+			if i > 0 {
+				w.Write([]byte{','})
+			}
+			w.Write(data)
+		} else {
+			w.Write(data)
+			w.Write([]byte{'\n'})
+		}
+	}
+	if this.batchFormat == HTTPBatchJSONArray {
+		w.Write([]byte{']'})
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// post 发送请求，返回是否可以重试以及服务端要求的等待时间
+func (this *HTTPWriter) post(body []byte) (bool, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, this.url, bytes.NewReader(body))
+	if err != nil {
+		this.observeWrite(0, err)
+		return false, 0, err
+	}
+	for key, values := range this.header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		if this.batchFormat == HTTPBatchJSONArray {
+			req.Header.Set("Content-Type", "application/json")
+		} else {
+			req.Header.Set("Content-Type", "application/x-ndjson")
+		}
+	}
+	if this.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	rsp, err := this.client.Do(req)
+	if err != nil {
+		this.observeWrite(0, err)
+		return true, 0, err
+	}
+	io.Copy(ioutil.Discard, rsp.Body)
+	rsp.Body.Close()
+
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		this.observeWrite(len(body), nil)
+		return false, 0, nil
+	}
+
+	err = fmt.Errorf("日志服务返回错误 %s", rsp.Status)
+	this.observeWrite(0, err)
+	var retry = rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= 500
+	var wait time.Duration
+	if seconds, e := strconv.Atoi(rsp.Header.Get("Retry-After")); e == nil && seconds > 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+	return retry, wait, err
+}
@@ -0,0 +1,115 @@
+package log4go_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPWriter_NDJSON(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Token") != "abc" || r.Header.Get("Content-Type") != "application/x-ndjson" || r.Header.Get("Content-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(zr)
+		mu.Lock()
+		bodies = append(bodies, string(data))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var hw = log4go.NewHTTPWriter(log4go.LevelTrace, server.URL, log4go.WithHTTPHeader("X-Token", "abc"), log4go.WithHTTPGzip(), log4go.WithHTTPBatch(2, time.Hour))
+	var l = log4go.New()
+	l.AddWriter("http", hw)
+	l.Infoln("1")
+	l.Infoln("2")
+	l.Infoln("3")
+	hw.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 || strings.Count(bodies[0], "\n") != 2 || strings.Count(bodies[1], "\n") != 1 || hw.Dropped() != 0 {
+		t.Fatalf("请求内容错误: %q %d", bodies, hw.Dropped())
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.Split(bodies[1], "\n")[0]), &obj); err != nil || obj["msg"] != "3" {
+		t.Fatalf("日志内容错误: %v %v", obj, err)
+	}
+}
+
+func TestHTTPWriter_Retry(t *testing.T) {
+	var requests int32
+	var received = make(chan []map[string]interface{}, 1)
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			var records []map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received <- records
+		}
+	}))
+	defer server.Close()
+
+	var hw = log4go.NewHTTPWriter(log4go.LevelTrace, server.URL, log4go.WithHTTPBatchFormat(log4go.HTTPBatchJSONArray),
+		log4go.WithHTTPBatch(10, 10*time.Millisecond), log4go.WithHTTPRetry(3, time.Millisecond, 5*time.Millisecond))
+	defer hw.Close()
+
+	var l = log4go.New()
+	l.AddWriter("http", hw)
+	l.Infoln("1")
+	l.Warnln("2")
+
+	select {
+	case records := <-received:
+		if len(records) != 2 || records[0]["msg"] != "1" || records[1]["level"] != "warning" {
+			t.Fatalf("请求内容错误: %v", records)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有收到日志")
+	}
+	if atomic.LoadInt32(&requests) != 3 {
+		t.Fatalf("重试次数错误: %d", requests)
+	}
+}
+
+func TestHTTPWriter_Dropped(t *testing.T) {
+	var requests int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	var hw = log4go.NewHTTPWriter(log4go.LevelTrace, server.URL, log4go.WithHTTPRetry(3, time.Millisecond, time.Millisecond))
+	hw.WriteRecord(&log4go.Record{Level: log4go.LevelInfo, Message: "1"})
+	hw.WriteRecord(&log4go.Record{Level: log4go.LevelInfo, Message: "2"})
+	hw.Close()
+
+	// 4xx 不重试，整批丢弃
+	if atomic.LoadInt32(&requests) != 1 || hw.Dropped() != 2 {
+		t.Fatalf("丢弃的日志数量错误: %d %d", requests, hw.Dropped())
+	}
+}
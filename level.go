@@ -0,0 +1,269 @@
+package log4go
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type Level int
+
+// 内置级别之间预留了间隔，用于注册自定义级别，参考 RegisterLevel。
+// 注意：早期版本中内置级别的数值为 0 到 6，现在为 0、10 到 60，如 LevelDebug 由 1 变为 10，
+// 以数值形式保存的级别（如配置文件、数据库中）需要乘以 10，或者改为使用 info 等名称
+const (
+	LevelTrace   Level = iota * 10 // "Trace
+	LevelDebug                     // "Debug"
+	LevelInfo                      // "Info"
+	LevelWarning                   // "Warning"
+	LevelError                     // "Error"
+	LevelPanic                     // "Panic"
+	LevelFatal                     // "Fatal"
+)
+
+// LevelDef 描述一个日志级别
+type LevelDef struct {
+	Name      string // 完整名称，如 NOTICE
+	ShortName string // 简短名称，为空时取 Name 的第一个字符，如 [N]
+	Color     Color
+	// 对应 RFC 5424 中的 severity，小于等于 0 时沿用相邻的较低级别
+	SyslogSeverity int
+}
+
+var levelRegistry = struct {
+	mu     sync.RWMutex
+	defs   map[Level]LevelDef
+	levels []Level
+}{
+	defs: map[Level]LevelDef{
+		LevelTrace:   {Name: "TRACE", ShortName: "[T]", Color: ColorWhite, SyslogSeverity: 7},
+		LevelDebug:   {Name: "DEBUG", ShortName: "[D]", Color: ColorGreen, SyslogSeverity: 7},
+		LevelInfo:    {Name: "INFO", ShortName: "[I]", Color: ColorBlue, SyslogSeverity: 6},
+		LevelWarning: {Name: "WARNING", ShortName: "[W]", Color: ColorYellow, SyslogSeverity: 4},
+		LevelError:   {Name: "ERROR", ShortName: "[E]", Color: ColorMagenta, SyslogSeverity: 3},
+		LevelPanic:   {Name: "PANIC", ShortName: "[P]", Color: ColorRed, SyslogSeverity: 2},
+		LevelFatal:   {Name: "FATAL", ShortName: "[F]", Color: ColorRed, SyslogSeverity: 1},
+	},
+	levels: builtinLevels,
+}
+
+var builtinLevels = []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarning, LevelError, LevelPanic, LevelFatal}
+
+var levelAliases = map[string]Level{
+	"WARN": LevelWarning,
+	"ERR":  LevelError,
+}
+
+// RegisterLevel 注册自定义日志级别，级别、名称及简短名称都不能与已注册的级别相同，重复注册相同的定义时不做处理，如：
+//
+//	const LevelNotice = log4go.LevelInfo + 5
+//	log4go.RegisterLevel(LevelNotice, log4go.LevelDef{Name: "NOTICE", Color: log4go.ColorSkyBlue})
+func RegisterLevel(level Level, def LevelDef) error {
+	def.Name = strings.ToUpper(strings.TrimSpace(def.Name))
+	if def.Name == "" {
+		return errors.New("日志级别名称不能为空")
+	}
+	if def.ShortName == "" {
+		var r, _ = utf8.DecodeRuneInString(def.Name)
+		def.ShortName = "[" + string(r) + "]"
+	}
+
+	levelRegistry.mu.Lock()
+	defer levelRegistry.mu.Unlock()
+
+	if def.SyslogSeverity <= 0 {
+		def.SyslogSeverity = 7
+		for _, l := range levelRegistry.levels {
+			if l < level {
+				def.SyslogSeverity = levelRegistry.defs[l].SyslogSeverity
+			}
+		}
+	}
+
+	if exists, ok := levelRegistry.defs[level]; ok {
+		// 重复注册相同的定义时忽略，如多次调用的初始化代码
+		if exists == def {
+			return nil
+		}
+		return fmt.Errorf("日志级别 %d 已被 %s 使用", int(level), exists.Name)
+	}
+	if _, ok := levelAliases[def.Name]; ok {
+		return fmt.Errorf("日志级别名称 %s 已存在", def.Name)
+	}
+	for _, exists := range levelRegistry.defs {
+		if exists.Name == def.Name {
+			return fmt.Errorf("日志级别名称 %s 已存在", def.Name)
+		}
+		// 简短名称相同时无法区分文本输出及 ParseLevel 的结果
+		if strings.EqualFold(exists.ShortName, def.ShortName) {
+			return fmt.Errorf("日志级别简短名称 %s 已被 %s 使用，请通过 ShortName 指定其它名称", def.ShortName, exists.Name)
+		}
+	}
+
+	levelRegistry.defs[level] = def
+	levelRegistry.levels = append(levelRegistry.levels[:len(levelRegistry.levels):len(levelRegistry.levels)], level)
+	sort.Slice(levelRegistry.levels, func(i, j int) bool {
+		return levelRegistry.levels[i] < levelRegistry.levels[j]
+	})
+	return nil
+}
+
+// Levels 返回所有已注册的日志级别，按从低到高排序
+func Levels() []Level {
+	levelRegistry.mu.RLock()
+	defer levelRegistry.mu.RUnlock()
+	var levels = make([]Level, len(levelRegistry.levels))
+	copy(levels, levelRegistry.levels)
+	return levels
+}
+
+func lookupLevel(level Level) (LevelDef, bool) {
+	levelRegistry.mu.RLock()
+	defer levelRegistry.mu.RUnlock()
+	def, ok := levelRegistry.defs[level]
+	return def, ok
+}
+
+func (l Level) Registered() bool {
+	_, ok := lookupLevel(l)
+	return ok
+}
+
+func (l Level) String() string {
+	if def, ok := lookupLevel(l); ok {
+		return def.Name
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// ShortName 返回形如 [I] 的级别名称
+func (l Level) ShortName() string {
+	if def, ok := lookupLevel(l); ok {
+		return def.ShortName
+	}
+	return fmt.Sprintf("[%d]", int(l))
+}
+
+func (l Level) Color() Color {
+	if def, ok := lookupLevel(l); ok {
+		return def.Color
+	}
+	return ColorNone
+}
+
+func (l Level) SyslogSeverity() int {
+	if def, ok := lookupLevel(l); ok {
+		return def.SyslogSeverity
+	}
+	if l < LevelTrace {
+		return 7
+	}
+	// 未注册的级别取其下方最接近的已注册级别
+	var severity = 7
+	for _, level := range Levels() {
+		if level < l {
+			severity = level.SyslogSeverity()
+		}
+	}
+	return severity
+}
+
+// MarshalText 返回小写的级别名称，未注册的级别（如介于内置级别之间的阈值）返回其数值
+func (l Level) MarshalText() ([]byte, error) {
+	if !l.Registered() {
+		return []byte(strconv.Itoa(int(l))), nil
+	}
+	return []byte(strings.ToLower(l.String())), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	if l == nil {
+		return errors.New("无法解析日志级别到 nil 指针")
+	}
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseLevel 解析日志级别，不区分大小写，支持 info、INFO、I、[I] 及数字形式
+func ParseLevel(s string) (Level, error) {
+	var name = strings.ToUpper(strings.TrimSpace(s))
+	if name == "" {
+		return LevelTrace, errors.New("日志级别不能为空")
+	}
+
+	if level, ok := levelAliases[name]; ok {
+		return level, nil
+	}
+
+	levelRegistry.mu.RLock()
+	for _, level := range levelRegistry.levels {
+		var def = levelRegistry.defs[level]
+		if name == def.Name || name == strings.ToUpper(def.ShortName) {
+			levelRegistry.mu.RUnlock()
+			return level, nil
+		}
+	}
+	// 不带括号的单个字母只匹配内置级别，避免与自定义级别的首字母冲突
+	for _, level := range builtinLevels {
+		if "["+name+"]" == levelRegistry.defs[level].ShortName {
+			levelRegistry.mu.RUnlock()
+			return level, nil
+		}
+	}
+	levelRegistry.mu.RUnlock()
+
+	// 数字形式可以为未注册的级别，用作介于已注册级别之间的阈值
+	if n, err := strconv.Atoi(name); err == nil {
+		return Level(n), nil
+	}
+
+	return LevelTrace, fmt.Errorf("未知的日志级别 %q", s)
+}
+
+type LevelStyle int
+
+const (
+	LevelStyleShort  LevelStyle = iota // [I]
+	LevelStyleFull                     // INFO
+	LevelStyleLower                    // info
+	LevelStyleSyslog                   // <6>
+)
+
+func (s LevelStyle) Format(level Level) string {
+	switch s {
+	case LevelStyleFull:
+		return level.String()
+	case LevelStyleLower:
+		return strings.ToLower(level.String())
+	case LevelStyleSyslog:
+		return "<" + strconv.Itoa(level.SyslogSeverity()) + ">"
+	}
+	return level.ShortName()
+}
+
+var levelStyleNames = []string{"short", "full", "lower", "syslog"}
+
+func (s LevelStyle) String() string {
+	if s < LevelStyleShort || int(s) >= len(levelStyleNames) {
+		return fmt.Sprintf("LevelStyle(%d)", int(s))
+	}
+	return levelStyleNames[s]
+}
+
+func ParseLevelStyle(s string) (LevelStyle, error) {
+	var name = strings.ToLower(strings.TrimSpace(s))
+	for i, n := range levelStyleNames {
+		if name == n {
+			return LevelStyle(i), nil
+		}
+	}
+	return LevelStyleShort, fmt.Errorf("未知的日志级别样式 %q", s)
+}
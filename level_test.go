@@ -0,0 +1,138 @@
+package log4go_test
+
+import (
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	var tests = []struct {
+		s     string
+		level log4go.Level
+	}{
+		{"trace", log4go.LevelTrace},
+		{"DEBUG", log4go.LevelDebug},
+		{"I", log4go.LevelInfo},
+		{"[W]", log4go.LevelWarning},
+		{"warn", log4go.LevelWarning},
+		{" error ", log4go.LevelError},
+		{"50", log4go.LevelPanic},
+		{"15", log4go.LevelDebug + 5},
+		{"Fatal", log4go.LevelFatal},
+	}
+
+	for _, test := range tests {
+		level, err := log4go.ParseLevel(test.s)
+		if err != nil {
+			t.Fatalf("解析 %q 出错: %v", test.s, err)
+		}
+		if level != test.level {
+			t.Fatalf("解析 %q 期望得到 %v, 实际得到 %v", test.s, test.level, level)
+		}
+	}
+
+	if _, err := log4go.ParseLevel("verbose"); err == nil {
+		t.Fatal("解析未知的日志级别应该返回错误")
+	}
+}
+
+func TestLevel_Text(t *testing.T) {
+	var cfg struct {
+		Level log4go.Level `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(`{"level":"warning"}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != log4go.LevelWarning {
+		t.Fatalf("期望得到 %v, 实际得到 %v", log4go.LevelWarning, cfg.Level)
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"level":"warning"}` {
+		t.Fatalf("序列化结果错误: %s", data)
+	}
+
+	// 未注册的级别序列化为数字，并且可以解析回来
+	cfg.Level = log4go.LevelInfo + 3
+	if data, err = json.Marshal(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"level":"23"}` {
+		t.Fatalf("序列化结果错误: %s", data)
+	}
+	cfg.Level = log4go.LevelTrace
+	if err = json.Unmarshal(data, &cfg); err != nil || cfg.Level != log4go.LevelInfo+3 {
+		t.Fatalf("解析未注册的日志级别错误: %v %v", cfg.Level, err)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	const LevelNotice = log4go.LevelInfo + 5
+	var err = log4go.RegisterLevel(LevelNotice, log4go.LevelDef{Name: "notice", Color: log4go.ColorSkyBlue, SyslogSeverity: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 重复注册相同的定义时不返回错误
+	if err = log4go.RegisterLevel(LevelNotice, log4go.LevelDef{Name: "NOTICE", Color: log4go.ColorSkyBlue, SyslogSeverity: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err = log4go.RegisterLevel(LevelNotice, log4go.LevelDef{Name: "audit"}); err == nil {
+		t.Fatal("重复注册同一个日志级别应该返回错误")
+	}
+	if err = log4go.RegisterLevel(log4go.LevelInfo+6, log4go.LevelDef{Name: "Info"}); err == nil {
+		t.Fatal("重复注册同一个日志级别名称应该返回错误")
+	}
+	if err = log4go.RegisterLevel(log4go.LevelInfo+6, log4go.LevelDef{Name: "informative"}); err == nil {
+		t.Fatal("日志级别简短名称与 [I] 相同时应该返回错误")
+	}
+	if err = log4go.RegisterLevel(log4go.LevelInfo+6, log4go.LevelDef{Name: "alert", ShortName: "[n]"}); err == nil {
+		t.Fatal("日志级别简短名称与 [N] 相同时应该返回错误")
+	}
+
+	// 默认的简短名称取第一个字符，而不是第一个字节
+	const LevelAudit = log4go.LevelWarning + 5
+	if err = log4go.RegisterLevel(LevelAudit, log4go.LevelDef{Name: "审计"}); err != nil {
+		t.Fatal(err)
+	}
+	if LevelAudit.ShortName() != "[审]" {
+		t.Fatalf("自定义日志级别简短名称错误: %q", LevelAudit.ShortName())
+	}
+
+	if LevelNotice.String() != "NOTICE" || LevelNotice.ShortName() != "[N]" || LevelNotice.SyslogSeverity() != 5 {
+		t.Fatalf("自定义日志级别信息错误: %s %s %d", LevelNotice, LevelNotice.ShortName(), LevelNotice.SyslogSeverity())
+	}
+
+	level, err := log4go.ParseLevel("Notice")
+	if err != nil || level != LevelNotice {
+		t.Fatalf("解析自定义日志级别错误: %v %v", level, err)
+	}
+
+	// 未注册的级别不应该引起 panic
+	var unknown = log4go.Level(1000)
+	if unknown.String() != "LEVEL(1000)" || log4go.LevelStyleSyslog.Format(unknown) != "<1>" {
+		t.Fatalf("未注册的日志级别信息错误: %s %s", unknown, log4go.LevelStyleSyslog.Format(unknown))
+	}
+}
+
+func TestLevelStyle_Format(t *testing.T) {
+	var tests = []struct {
+		style log4go.LevelStyle
+		name  string
+	}{
+		{log4go.LevelStyleShort, "[I]"},
+		{log4go.LevelStyleFull, "INFO"},
+		{log4go.LevelStyleLower, "info"},
+		{log4go.LevelStyleSyslog, "<6>"},
+	}
+
+	for _, test := range tests {
+		if name := test.style.Format(log4go.LevelInfo); name != test.name {
+			t.Fatalf("%v 期望得到 %s, 实际得到 %s", test.style, test.name, name)
+		}
+	}
+}
@@ -1,39 +1,18 @@
 package log4go
 
 import (
-	"fmt"
+	"bytes"
+	"context"
 	"io"
 	"os"
 	"path/filepath"
-	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type Level int
-
-const (
-	LevelTrace   Level = iota // "Trace
-	LevelDebug                // "Debug"
-	LevelInfo                 // "Info"
-	LevelWarning              // "Warning"
-	LevelError                // "Error"
-	LevelPanic                // "Panic"
-	LevelFatal                // "Fatal"
-)
-
-var (
-	LevelNames = []string{
-		"[T]",
-		"[D]",
-		"[I]",
-		"[W]",
-		"[E]",
-		"[P]",
-		"[F]",
-	}
-)
-
 type Option interface {
 	Apply(Logger)
 }
@@ -62,7 +41,38 @@ func WithInstance(s string) Option {
 	})
 }
 
+func WithSampler(s Sampler) Option {
+	return optionFunc(func(l Logger) {
+		l.SetSampler(s)
+	})
+}
+
+func WithRedactor(r *Redactor) Option {
+	return optionFunc(func(l Logger) {
+		l.SetRedactor(r)
+	})
+}
+
+// WithCallerSkip 查找日志的调用位置时额外跳过 n 层调用栈，用于封装了 Logger 的函数
+func WithCallerSkip(n int) Option {
+	return optionFunc(func(l Logger) {
+		if nl, ok := l.(*logger); ok {
+			nl.callerSkip += n
+		}
+	})
+}
+
 type Logger interface {
+	// Name 返回 Logger 的名称，根 Logger 的名称为空字符串
+	Name() string
+
+	// GetLogger 返回以当前 Logger 为祖先的命名 Logger，如 GetLogger("db") 返回 "<当前名称>/db"，
+	// 子 Logger 会将日志同时交给自身及所有祖先的 Writer，未设置的级别、调用栈及路径等设置沿用最近祖先的设置
+	GetLogger(name string) Logger
+
+	// With 返回应用了 opts 的子 Logger，子 Logger 与当前 Logger 共用 Writer 及其它设置
+	With(opts ...Option) Logger
+
 	SetService(service string)
 	Service() string
 
@@ -72,6 +82,26 @@ type Logger interface {
 	SetPrefix(prefix string)
 	Prefix() string
 
+	// SetLevel 设置全局的最低日志级别，低于该级别的日志不会交给任何 Writer 处理
+	SetLevel(level Level)
+	Level() Level
+	// ResetLevel 清除 SetLevel 设置的级别，恢复为沿用父 Logger 的级别
+	ResetLevel()
+
+	// SetSampler 设置采样器，达到级别要求的日志还需要经过 Sampler 才会交给 Writer 处理，
+	// 为 nil 时沿用父 Logger 的 Sampler
+	SetSampler(s Sampler)
+	Sampler() Sampler
+
+	// AddHook 添加 Hook，级别在 levels 中的日志记录交给 Writer 处理之前会先调用 hook，levels 为空时对所有级别生效，
+	// 子 Logger 的日志记录会依次经过祖先及自身的 Hook
+	AddHook(levels []Level, hook Hook)
+
+	// SetRedactor 设置 Redactor，在所有 Hook 之后、交给 Writer 处理之前隐藏日志记录中的敏感信息，
+	// 为 nil 时沿用父 Logger 的 Redactor
+	SetRedactor(r *Redactor)
+	Redactor() *Redactor
+
 	SetStackLevel(level Level)
 	StackLevel() Level
 
@@ -79,14 +109,29 @@ type Logger interface {
 	DisableStack()
 	PrintStack() bool
 
+	// SetStackMode 设置调用栈的输出方式，默认为 StackAll
+	SetStackMode(mode StackMode)
+	StackMode() StackMode
+	// SetStackLimit 设置调用栈的大小限制，StackAll 为最大字节数，StackCurrent 为最大帧数，小于等于 0 时使用默认值
+	SetStackLimit(limit int)
+	StackLimit() int
+
 	EnablePath()
 	DisablePath()
 	PrintPath() bool
 
 	WriteMessage(callDepth int, level Level, msg string)
-
-	AddWriter(name string, w Writer)
+	// WriteMessageContext 与 WriteMessage 相同，同时从 ctx 中提取字段，并和 fields 一起添加到日志记录中，ctx 可以为 nil
+	WriteMessageContext(ctx context.Context, callDepth int, level Level, msg string, fields ...Field)
+	// WriteRecord 将已经生成的日志记录交给 Writer 处理，用于对接其它日志库，
+	// r 中的 Logger、Service、Instance 和 Prefix 为空时使用当前 Logger 的设置
+	WriteRecord(r *Record)
+
+	// AddWriter 添加 Writer，filters 不为空时只有全部 Filter 都允许的日志记录才会交给该 Writer 处理
+	AddWriter(name string, w Writer, filters ...Filter)
 	RemoveWriter(name string)
+	Writer(name string) Writer
+	WriterInfos() []WriterInfo
 
 	Logf(format string, args ...interface{})
 	Logln(args ...interface{})
@@ -131,6 +176,21 @@ type Logger interface {
 	Fatalln(args ...interface{})
 	Fatal(args ...interface{})
 
+	TraceCtx(ctx context.Context, args ...interface{})
+	TraceCtxf(ctx context.Context, format string, args ...interface{})
+	DebugCtx(ctx context.Context, args ...interface{})
+	DebugCtxf(ctx context.Context, format string, args ...interface{})
+	InfoCtx(ctx context.Context, args ...interface{})
+	InfoCtxf(ctx context.Context, format string, args ...interface{})
+	WarnCtx(ctx context.Context, args ...interface{})
+	WarnCtxf(ctx context.Context, format string, args ...interface{})
+	ErrorCtx(ctx context.Context, args ...interface{})
+	ErrorCtxf(ctx context.Context, format string, args ...interface{})
+	PanicCtx(ctx context.Context, args ...interface{})
+	PanicCtxf(ctx context.Context, format string, args ...interface{})
+	FatalCtx(ctx context.Context, args ...interface{})
+	FatalCtxf(ctx context.Context, format string, args ...interface{})
+
 	Output(callDepth int, s string) error
 }
 
@@ -142,23 +202,150 @@ type Writer interface {
 	WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string)
 }
 
+// RecordWriter 由可以处理完整日志记录的 Writer 实现，Logger 优先调用 WriteRecord；
+// 未实现时调用 Writer.WriteMessage，字段会追加在 msg 之后，Logger 名称、函数名及调用栈等信息不再传递
+type RecordWriter interface {
+	Writer
+
+	WriteRecord(r *Record)
+}
+
+// LevelSetter 由可以在运行时调整级别的 Writer 实现，内置的 Writer 都已实现，
+// AdminHandler 及配置信息中的 level 需要 Writer 实现该接口
+type LevelSetter interface {
+	SetLevel(level Level)
+}
+
+// NewMessageRecord 由 Writer.WriteMessage 的参数生成日志记录，用于同时实现 RecordWriter 的 Writer，
+// logTime 无法解析时使用当前时间
+func NewMessageRecord(service, instance, prefix, logTime string, level Level, file string, line int, msg string) *Record {
+	var t, err = time.ParseInLocation(kTimeLayout, logTime, time.Local)
+	if err != nil {
+		t = time.Now()
+	}
+	return &Record{
+		Service:  service,
+		Instance: instance,
+		Prefix:   prefix,
+		Time:     t,
+		Level:    level,
+		File:     file,
+		Line:     line,
+		Message:  msg,
+	}
+}
+
+// WriterInfo 为 Writer 的运行状态
+type WriterInfo struct {
+	Name     string `json:"name"`
+	Level    Level  `json:"level"`
+	Filter   string `json:"filter,omitempty"`
+	Written  uint64 `json:"written"`
+	Filtered uint64 `json:"filtered"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+// DropCounter 由内部可能丢弃日志的 Writer 实现，如缓冲区已满
+type DropCounter interface {
+	Dropped() uint64
+}
+
+type writerEntry struct {
+	name     string
+	writer   Writer
+	filter   Filter
+	written  uint64
+	filtered uint64
+	// inflight 为正在使用该 Writer 的日志数量，移除之后需要等待其归零再关闭
+	inflight sync.WaitGroup
+}
+
+func newWriterEntry(name string, w Writer) *writerEntry {
+	if ow, ok := w.(observedWriter); ok {
+		ow.setObservedName(name)
+	}
+	return &writerEntry{name: name, writer: w}
+}
+
+func (this *writerEntry) allow(r *Record) bool {
+	if this.writer.Level() > r.Level {
+		return false
+	}
+	if this.filter != nil && !this.filter.Allow(r) {
+		atomic.AddUint64(&this.filtered, 1)
+		return false
+	}
+	atomic.AddUint64(&this.written, 1)
+	return true
+}
+
+func (this *writerEntry) write(r *Record) {
+	if rw, ok := this.writer.(RecordWriter); ok {
+		rw.WriteRecord(r)
+		return
+	}
+
+	var msg = r.Message
+	if len(r.Fields) > 0 {
+		var buf bytes.Buffer
+		var body = strings.TrimRight(msg, "\n")
+		buf.WriteString(body)
+		writeTextFields(&buf, r.Fields)
+		buf.WriteString(msg[len(body):])
+		msg = buf.String()
+	}
+	this.writer.WriteMessage(r.Service, r.Instance, r.Prefix, r.Time.Format(kTimeLayout), r.Level, r.File, r.Line, msg)
+}
+
+func (this *writerEntry) info(name string) WriterInfo {
+	var info = WriterInfo{
+		Name:     name,
+		Level:    this.writer.Level(),
+		Written:  atomic.LoadUint64(&this.written),
+		Filtered: atomic.LoadUint64(&this.filtered),
+	}
+	if this.filter != nil {
+		info.Filter = filterString(this.filter)
+	}
+	if dc, ok := this.writer.(DropCounter); ok {
+		info.Dropped = dc.Dropped()
+	}
+	return info
+}
+
 type logger struct {
-	mu         sync.Mutex
-	writers    map[string]Writer
+	mu sync.Mutex
+	// tree 由同一棵树上的所有 Logger 共享，写日志时只在获取 Writer 时持有读锁，
+	// 整体替换 Writer 时持有写锁，保证每条日志看到的 Writer 是一致的
+	tree       *sync.RWMutex
+	name       string
+	parent     *logger
+	children   map[string]*logger
+	callerSkip int
+	level      int32
+	writers    map[string]*writerEntry
 	prefix     string
 	service    string
 	instance   string
-	printStack bool
+	printStack toggle
 	stackLevel Level
-	printPath  bool
+	stackMode  StackMode
+	stackLimit int
+	printPath  toggle
+	sampler    Sampler
+	hooks      []*hookEntry
+	redactor   *Redactor
 }
 
 func New(opts ...Option) Logger {
 	var l = &logger{}
-	l.writers = make(map[string]Writer)
+	l.tree = &sync.RWMutex{}
+	l.writers = make(map[string]*writerEntry)
+	l.level = int32(LevelTrace)
 	l.stackLevel = LevelPanic
-	l.printStack = false
-	l.printPath = true
+	l.printStack = toggleOff
+	l.stackMode = StackAll
+	l.printPath = toggleOn
 	for _, opt := range opts {
 		opt.Apply(l)
 	}
@@ -174,6 +361,9 @@ func (this *logger) SetService(service string) {
 func (this *logger) Service() string {
 	this.mu.Lock()
 	defer this.mu.Unlock()
+	if this.service == "" && this.parent != nil {
+		return this.parent.Service()
+	}
 	return this.service
 }
 
@@ -186,6 +376,9 @@ func (this *logger) SetInstance(instance string) {
 func (this *logger) Instance() string {
 	this.mu.Lock()
 	defer this.mu.Unlock()
+	if this.instance == "" && this.parent != nil {
+		return this.parent.Instance()
+	}
 	return this.instance
 }
 
@@ -198,9 +391,108 @@ func (this *logger) SetPrefix(prefix string) {
 func (this *logger) Prefix() string {
 	this.mu.Lock()
 	defer this.mu.Unlock()
+	if this.prefix == "" && this.parent != nil {
+		return this.parent.Prefix()
+	}
 	return this.prefix
 }
 
+func (this *logger) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *logger) Level() Level {
+	for l := this; l != nil; l = l.parent {
+		if level := atomic.LoadInt32(&l.level); level != kLevelNotSet {
+			return Level(level)
+		}
+	}
+	return LevelTrace
+}
+
+func (this *logger) ResetLevel() {
+	if this.parent == nil {
+		atomic.StoreInt32(&this.level, int32(LevelTrace))
+		return
+	}
+	atomic.StoreInt32(&this.level, kLevelNotSet)
+}
+
+func (this *logger) SetSampler(s Sampler) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.sampler = s
+}
+
+func (this *logger) Sampler() Sampler {
+	for l := this; l != nil; l = l.parent {
+		l.mu.Lock()
+		var s = l.sampler
+		l.mu.Unlock()
+		if s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+func (this *logger) AddHook(levels []Level, hook Hook) {
+	if hook == nil {
+		return
+	}
+	var entry = newHookEntry(levels, hook)
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	// 复制一份，fire 时不需要持有锁
+	var hooks = make([]*hookEntry, 0, len(this.hooks)+1)
+	hooks = append(hooks, this.hooks...)
+	this.hooks = append(hooks, entry)
+}
+
+func (this *logger) getHooks() []*hookEntry {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.hooks
+}
+
+// fire 从根 Logger 开始依次调用 Hook
+func (this *logger) fire(r *Record) {
+	if this.parent != nil {
+		this.parent.fire(r)
+	}
+	for _, entry := range this.getHooks() {
+		entry.fire(r)
+	}
+}
+
+func (this *logger) SetRedactor(r *Redactor) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.redactor = r
+}
+
+func (this *logger) Redactor() *Redactor {
+	for l := this; l != nil; l = l.parent {
+		l.mu.Lock()
+		var r = l.redactor
+		l.mu.Unlock()
+		if r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+func (this *logger) sample(r *Record) bool {
+	var s = this.Sampler()
+	var ok = s == nil || s.Allow(r)
+	if o := getObserver(); o != nil {
+		o.ObserveRecord(r.Logger, r.Level, ok)
+	}
+	return ok
+}
+
 func (this *logger) SetStackLevel(level Level) {
 	this.mu.Lock()
 	defer this.mu.Unlock()
@@ -208,243 +500,482 @@ func (this *logger) SetStackLevel(level Level) {
 }
 
 func (this *logger) StackLevel() Level {
-	this.mu.Lock()
-	defer this.mu.Unlock()
-	return this.stackLevel
+	for l := this; l != nil; l = l.parent {
+		l.mu.Lock()
+		var level = l.stackLevel
+		l.mu.Unlock()
+		if level != kLevelNotSet {
+			return level
+		}
+	}
+	return LevelPanic
 }
 
 func (this *logger) EnableStack() {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	this.printStack = true
+	this.printStack = toggleOn
 }
 
 func (this *logger) DisableStack() {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	this.printStack = false
+	this.printStack = toggleOff
 }
 
 func (this *logger) PrintStack() bool {
+	for l := this; l != nil; l = l.parent {
+		l.mu.Lock()
+		var t = l.printStack
+		l.mu.Unlock()
+		if t != toggleNotSet {
+			return t == toggleOn
+		}
+	}
+	return false
+}
+
+func (this *logger) SetStackMode(mode StackMode) {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	return this.printStack
+	this.stackMode = mode
+}
+
+func (this *logger) StackMode() StackMode {
+	for l := this; l != nil; l = l.parent {
+		l.mu.Lock()
+		var mode = l.stackMode
+		l.mu.Unlock()
+		if mode != kStackModeNotSet {
+			return mode
+		}
+	}
+	return StackAll
+}
+
+func (this *logger) SetStackLimit(limit int) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.stackLimit = limit
+}
+
+func (this *logger) StackLimit() int {
+	for l := this; l != nil; l = l.parent {
+		l.mu.Lock()
+		var limit = l.stackLimit
+		l.mu.Unlock()
+		if limit != kStackLimitNotSet {
+			return limit
+		}
+	}
+	return 0
 }
 
 func (this *logger) EnablePath() {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	this.printPath = true
+	this.printPath = toggleOn
 }
 
 func (this *logger) DisablePath() {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	this.printPath = false
+	this.printPath = toggleOff
 }
 
 func (this *logger) PrintPath() bool {
-	this.mu.Lock()
-	defer this.mu.Unlock()
-	return this.printPath
+	for l := this; l != nil; l = l.parent {
+		l.mu.Lock()
+		var t = l.printPath
+		l.mu.Unlock()
+		if t != toggleNotSet {
+			return t == toggleOn
+		}
+	}
+	return true
 }
 
 func (this *logger) WriteMessage(callDepth int, level Level, msg string) {
-	this.mu.Lock()
-	defer this.mu.Unlock()
+	this.output(nil, callDepth+1, level, msg, nil)
+}
+
+func (this *logger) WriteMessageContext(ctx context.Context, callDepth int, level Level, msg string, fields ...Field) {
+	this.output(ctx, callDepth+1, level, msg, fields)
+}
 
-	var file string
-	var line int
+func (this *logger) output(ctx context.Context, callDepth int, level Level, msg string, fields []Field) {
+	if level < this.Level() {
+		return
+	}
 
-	_, file, line, ok := runtime.Caller(callDepth)
-	if ok {
-		if this.printPath == false {
+	var r = &Record{
+		Logger:   this.name,
+		Service:  this.Service(),
+		Instance: this.Instance(),
+		Prefix:   this.Prefix(),
+		Time:     time.Now(),
+		Level:    level,
+		Message:  msg,
+		Context:  ctx,
+	}
+	if ctx != nil {
+		r.Fields = fieldsFromContext(ctx)
+	}
+	r.Fields = append(r.Fields, fields...)
+
+	// 在获取调用位置和调用栈之前采样，被丢弃的日志不产生额外的开销
+	if !this.sample(r) {
+		return
+	}
+
+	var file = "???"
+	var line = -1
+	var function string
+
+	if frame, ok := callerFrame(callDepth+this.callerSkip, nil); ok {
+		file, line, function = frame.File, frame.Line, shortFunction(frame.Function)
+		if this.PrintPath() == false {
 			_, file = filepath.Split(file)
 		}
-	} else {
-		file = "???"
-		line = -1
 	}
 
-	if this.printStack && level >= this.stackLevel {
-		var buf [4096]byte
-		n := runtime.Stack(buf[:], true)
-		msg += string(buf[:n])
-		msg += "\n"
+	r.File, r.Line, r.Function = file, line, function
+
+	if this.PrintStack() && level >= this.StackLevel() {
+		if this.StackMode() == StackCurrent {
+			r.Stack = currentStack(callDepth+this.callerSkip, this.StackLimit())
+		} else {
+			r.Message += allStack(this.StackLimit())
+			r.Message += "\n"
+		}
+	}
+
+	this.dispatch(r)
+}
+
+func (this *logger) WriteRecord(r *Record) {
+	if r == nil || r.Level < this.Level() {
+		return
+	}
+
+	if r.Logger == "" {
+		r.Logger = this.name
 	}
+	if r.Service == "" {
+		r.Service = this.Service()
+	}
+	if r.Instance == "" {
+		r.Instance = this.Instance()
+	}
+	if r.Prefix == "" {
+		r.Prefix = this.Prefix()
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	if r.File != "" && this.PrintPath() == false {
+		_, r.File = filepath.Split(r.File)
+	}
+	if r.Context != nil {
+		r.Fields = append(r.Fields, fieldsFromContext(r.Context)...)
+	}
+	if !this.sample(r) {
+		return
+	}
+
+	this.dispatch(r)
+}
+
+// dispatch 依次交给自身及所有祖先的 Writer 处理。
+// 调用 Hook、Filter 及 Writer 时不持有锁，其中可以再次写日志，也不会阻塞 RemoveWriter 及 Config.Apply
+func (this *logger) dispatch(r *Record) {
+	this.fire(r)
+	if rd := this.Redactor(); rd != nil {
+		rd.Redact(r)
+	}
+
+	var entries = this.acquireEntries()
+	defer releaseEntries(entries)
+
+	var o = getObserver()
+	for _, entry := range entries {
+		if !entry.allow(r) {
+			continue
+		}
+		if o == nil {
+			entry.write(r)
+			continue
+		}
+		var begin = time.Now()
+		entry.write(r)
+		o.ObserveDispatch(entry.name, r.Level, time.Since(begin))
+	}
+}
 
-	var now = time.Now()
-	var logTime = now.Format("2006/01/02 15:04:05.000000")
+// acquireEntries 返回自身及所有祖先的 Writer，在 releaseEntries 之前这些 Writer 不会被关闭
+func (this *logger) acquireEntries() []*writerEntry {
+	this.tree.RLock()
+	defer this.tree.RUnlock()
 
-	for _, w := range this.writers {
-		if w.Level() <= level {
-			w.WriteMessage(this.service, this.instance, this.prefix, logTime, level, file, line, msg)
+	var entries []*writerEntry
+	for l := this; l != nil; l = l.parent {
+		for _, entry := range l.entries() {
+			entry.inflight.Add(1)
+			entries = append(entries, entry)
 		}
 	}
+	return entries
+}
+
+func releaseEntries(entries []*writerEntry) {
+	for _, entry := range entries {
+		entry.inflight.Done()
+	}
 }
 
-func (this *logger) AddWriter(name string, w Writer) {
+func (this *logger) entries() []*writerEntry {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	this.writers[name] = w
+	var entries = make([]*writerEntry, 0, len(this.writers))
+	for _, entry := range this.writers {
+		entries = append(entries, entry)
+	}
+	return entries
 }
 
-func (this *logger) RemoveWriter(name string) {
+func (this *logger) AddWriter(name string, w Writer, filters ...Filter) {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	var w = this.writers[name]
-	if w != nil {
-		w.Close()
+	var entry = newWriterEntry(name, w)
+	if filters = compactFilters(filters); len(filters) == 1 {
+		entry.filter = filters[0]
+	} else if len(filters) > 1 {
+		entry.filter = And(filters...)
 	}
+	this.writers[name] = entry
+}
+
+func (this *logger) RemoveWriter(name string) {
+	// 持有写锁时移除，之后的日志不会再使用该 Writer
+	this.tree.Lock()
+	this.mu.Lock()
+	var entry = this.writers[name]
 	delete(this.writers, name)
+	this.mu.Unlock()
+	this.tree.Unlock()
+
+	closeWriters(entry)
+}
+
+// closeWriters 等待正在使用 Writer 的日志写入完成之后再关闭，entries 需要已经从 Logger 中移除
+func closeWriters(entries ...*writerEntry) {
+	for _, entry := range entries {
+		if entry != nil {
+			entry.inflight.Wait()
+			entry.writer.Close()
+		}
+	}
+}
+
+func (this *logger) Writer(name string) Writer {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if entry := this.writers[name]; entry != nil {
+		return entry.writer
+	}
+	return nil
+}
+
+func (this *logger) WriterInfos() []WriterInfo {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	var infos = make([]WriterInfo, 0, len(this.writers))
+	for name, entry := range this.writers {
+		infos = append(infos, entry.info(name))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
 }
 
 func (this *logger) Logf(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Logln(args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Log(args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) L(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Tracef(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Traceln(args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Trace(args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) T(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Printf(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Println(args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Print(args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) P(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func (this *logger) Debugf(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelDebug, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func (this *logger) Debugln(args ...interface{}) {
-	this.WriteMessage(2, LevelDebug, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func (this *logger) Debug(args ...interface{}) {
-	this.WriteMessage(2, LevelDebug, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func (this *logger) D(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelDebug, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func (this *logger) Infof(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelInfo, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func (this *logger) Infoln(args ...interface{}) {
-	this.WriteMessage(2, LevelInfo, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func (this *logger) Info(args ...interface{}) {
-	this.WriteMessage(2, LevelInfo, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func (this *logger) I(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelInfo, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func (this *logger) Warnf(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelWarning, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func (this *logger) Warnln(args ...interface{}) {
-	this.WriteMessage(2, LevelWarning, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func (this *logger) Warn(args ...interface{}) {
-	this.WriteMessage(2, LevelWarning, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func (this *logger) W(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelWarning, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func (this *logger) Errorf(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelError, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelError, msg, fields)
 	os.Exit(-1)
 }
 
 func (this *logger) Errorln(args ...interface{}) {
-	this.WriteMessage(2, LevelError, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelError, msg, fields)
 	os.Exit(-1)
 }
 
 func (this *logger) Error(args ...interface{}) {
-	this.WriteMessage(2, LevelError, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelError, msg, fields)
 	os.Exit(-1)
 }
 
 func (this *logger) E(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelError, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelError, msg, fields)
 	os.Exit(-1)
 }
 
 func (this *logger) Panicf(format string, args ...interface{}) {
-	var msg = fmt.Sprintf(format, args...)
-	this.WriteMessage(2, LevelPanic, msg)
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelPanic, msg, fields)
 	panic(msg)
 }
 
 func (this *logger) Panicln(args ...interface{}) {
-	var msg = fmt.Sprintln(args...)
-	this.WriteMessage(2, LevelPanic, msg)
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelPanic, msg, fields)
 	panic(msg)
 }
 
 func (this *logger) Panic(args ...interface{}) {
-	var msg = fmt.Sprintln(args...)
-	this.WriteMessage(2, LevelPanic, msg)
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelPanic, msg, fields)
 	panic(msg)
 }
 
 func (this *logger) Fatalf(format string, args ...interface{}) {
-	this.WriteMessage(2, LevelFatal, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	this.output(nil, 2, LevelFatal, msg, fields)
 	//os.Exit(-1)
 }
 
 func (this *logger) Fatalln(args ...interface{}) {
-	this.WriteMessage(2, LevelFatal, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelFatal, msg, fields)
 	//os.Exit(-1)
 }
 
 func (this *logger) Fatal(args ...interface{}) {
-	this.WriteMessage(2, LevelFatal, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	this.output(nil, 2, LevelFatal, msg, fields)
 	//os.Exit(-1)
 }
 
@@ -453,13 +984,13 @@ func (this *logger) Output(callDepth int, s string) error {
 	return nil
 }
 
-var sharedLogger Logger
+var sharedLogger *logger
 var once sync.Once
 
 func init() {
 	once.Do(func() {
-		sharedLogger = New()
-		sharedLogger.AddWriter("stdout", NewStdWriter(LevelTrace))
+		sharedLogger = New().(*logger)
+		configureFromEnv(sharedLogger)
 	})
 }
 
@@ -467,6 +998,22 @@ func SharedLogger() Logger {
 	return sharedLogger
 }
 
+func SetLevel(level Level) {
+	sharedLogger.SetLevel(level)
+}
+
+func SetSampler(s Sampler) {
+	sharedLogger.SetSampler(s)
+}
+
+func AddHook(levels []Level, hook Hook) {
+	sharedLogger.AddHook(levels, hook)
+}
+
+func SetRedactor(r *Redactor) {
+	sharedLogger.SetRedactor(r)
+}
+
 func SetPrefix(prefix string) {
 	sharedLogger.SetPrefix(prefix)
 }
@@ -475,8 +1022,8 @@ func Prefix() string {
 	return sharedLogger.Prefix()
 }
 
-func AddWriter(name string, w Writer) {
-	sharedLogger.AddWriter(name, w)
+func AddWriter(name string, w Writer, filters ...Filter) {
+	sharedLogger.AddWriter(name, w, filters...)
 }
 
 func RemoveWriter(name string) {
@@ -484,147 +1031,178 @@ func RemoveWriter(name string) {
 }
 
 func Logf(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Logln(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Log(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func L(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Tracef(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Traceln(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Trace(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func T(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Printf(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Println(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Print(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func P(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelTrace, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelTrace, msg, fields)
 }
 
 func Debugf(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelDebug, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func Debugln(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelDebug, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func Debug(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelDebug, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func D(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelDebug, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelDebug, msg, fields)
 }
 
 func Infof(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelInfo, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func Infoln(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelInfo, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func Info(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelInfo, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func I(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelInfo, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelInfo, msg, fields)
 }
 
 func Errorf(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelError, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelError, msg, fields)
 }
 
 func Errorln(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelError, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelError, msg, fields)
 }
 
 func Error(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelError, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelError, msg, fields)
 }
 
 func E(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelError, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelError, msg, fields)
 }
 
 func Warnf(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelWarning, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func Warnln(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelWarning, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func Warn(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelWarning, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func W(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelWarning, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelWarning, msg, fields)
 }
 
 func Panicf(format string, args ...interface{}) {
-	var msg = fmt.Sprintf(format, args...)
-	sharedLogger.WriteMessage(2, LevelPanic, msg)
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelPanic, msg, fields)
 	panic(msg)
 }
 
 func Panicln(args ...interface{}) {
-	var msg = fmt.Sprintln(args...)
-	sharedLogger.WriteMessage(2, LevelPanic, msg)
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelPanic, msg, fields)
 	panic(msg)
 }
 
 func Panic(args ...interface{}) {
-	var msg = fmt.Sprintln(args...)
-	sharedLogger.WriteMessage(2, LevelPanic, msg)
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelPanic, msg, fields)
 	panic(msg)
 }
 
 func Fatalf(format string, args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelFatal, fmt.Sprintf(format, args...))
+	var msg, fields = sprintf(format, args)
+	sharedLogger.output(nil, 2, LevelFatal, msg, fields)
 	//os.Exit(-1)
 }
 
 func Fatalln(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelFatal, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelFatal, msg, fields)
 	//os.Exit(-1)
 }
 
 func Fatal(args ...interface{}) {
-	sharedLogger.WriteMessage(2, LevelFatal, fmt.Sprintln(args...))
+	var msg, fields = sprintln(args)
+	sharedLogger.output(nil, 2, LevelFatal, msg, fields)
 	//os.Exit(-1)
 }
 
@@ -3,7 +3,11 @@ package log4go_test
 import (
 	"github.com/smartwalle/log4go"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -31,3 +35,236 @@ func BenchmarkPrintln(b *testing.B) {
 		log4go.Println("1", "2", "3", "4", "5")
 	}
 }
+
+func TestLogger_SetLevel(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	l.SetLevel(log4go.LevelInfo)
+	l.Debugln("debug")
+	l.Infoln("info")
+
+	w.SetLevel(log4go.LevelWarning)
+	l.Infoln("info")
+	l.Warnln("warn")
+
+	var records = w.Records()
+	if len(records) != 2 || records[0].Level != log4go.LevelInfo || records[1].Level != log4go.LevelWarning {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+}
+
+func TestLogger_GetLogger(t *testing.T) {
+	var root = log4go.New(log4go.WithService("[svc]"))
+	var rw = &memoryWriter{}
+	root.AddWriter("memory", rw)
+
+	var db = root.GetLogger("app/db")
+	if db != root.GetLogger("app").GetLogger("db") || db.Name() != "app/db" {
+		t.Fatalf("命名 Logger 错误: %s", db.Name())
+	}
+
+	var dw = &memoryWriter{}
+	db.AddWriter("db", dw)
+
+	root.GetLogger("app").SetLevel(log4go.LevelInfo)
+	db.SetLevel(log4go.LevelWarning)
+	var api = root.GetLogger("app/api")
+
+	db.Infoln("db info")
+	db.Warnln("db warn")
+	api.Debugln("api debug")
+	api.Infoln("api info")
+
+	var records = rw.Records()
+	if len(records) != 2 || records[0].Logger != "app/db" || records[1].Logger != "app/api" || records[1].Service != "[svc]" {
+		t.Fatalf("根 Logger 收到的日志记录错误: %v", records)
+	}
+	if records = dw.Records(); len(records) != 1 || records[0].Level != log4go.LevelWarning {
+		t.Fatalf("子 Logger 收到的日志记录错误: %v", records)
+	}
+
+	db.ResetLevel()
+	if db.Level() != log4go.LevelInfo {
+		t.Fatalf("ResetLevel 之后应该沿用父 Logger 的级别, 实际为 %v", db.Level())
+	}
+}
+
+func TestLogger_GetLoggerInheritSettings(t *testing.T) {
+	var root = log4go.New()
+	var w = &memoryWriter{}
+	root.AddWriter("memory", w)
+
+	// 子 Logger 通常在包初始化时创建，之后对根 Logger 的修改也应该生效
+	var db = root.GetLogger("app/db")
+	var api = root.GetLogger("app/api")
+	root.DisablePath()
+	root.EnableStack()
+	root.SetStackLevel(log4go.LevelWarning)
+	root.SetStackMode(log4go.StackCurrent)
+	root.SetStackLimit(3)
+
+	if db.PrintPath() || !db.PrintStack() || db.StackLevel() != log4go.LevelWarning || db.StackMode() != log4go.StackCurrent || db.StackLimit() != 3 {
+		t.Fatalf("子 Logger 没有沿用根 Logger 的设置: %v %v %v %v %v", db.PrintPath(), db.PrintStack(), db.StackLevel(), db.StackMode(), db.StackLimit())
+	}
+
+	db.Warnln("db warn")
+	var records = w.Records()
+	if len(records) != 1 || strings.Contains(records[0].File, "/") || len(records[0].Stack) == 0 || len(records[0].Stack) > 3 {
+		t.Fatalf("日志记录错误: %+v", records)
+	}
+
+	// 子 Logger 自身的设置优先，并且不影响兄弟 Logger
+	db.EnablePath()
+	db.DisableStack()
+	db.SetStackLimit(0)
+	root.SetStackLimit(5)
+	if !db.PrintPath() || db.PrintStack() || db.StackLimit() != 0 {
+		t.Fatalf("子 Logger 的设置错误: %v %v %v", db.PrintPath(), db.PrintStack(), db.StackLimit())
+	}
+	if api.PrintPath() || !api.PrintStack() || api.StackLimit() != 5 {
+		t.Fatalf("兄弟 Logger 的设置错误: %v %v %v", api.PrintPath(), api.PrintStack(), api.StackLimit())
+	}
+}
+
+// legacyWriter 只实现 Writer 接口，不处理完整的日志记录，也不能调整级别
+type legacyWriter struct {
+	level    log4go.Level
+	messages []string
+}
+
+func (this *legacyWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (this *legacyWriter) Close() error {
+	return nil
+}
+
+func (this *legacyWriter) Level() log4go.Level {
+	return this.level
+}
+
+func (this *legacyWriter) WriteMessage(service, instance, prefix, logTime string, level log4go.Level, file string, line int, msg string) {
+	this.messages = append(this.messages, strings.Join([]string{service, prefix, logTime, level.String(), filepath.Base(file), msg}, "|"))
+}
+
+func TestLogger_LegacyWriter(t *testing.T) {
+	var l = log4go.New(log4go.WithService("svc"), log4go.WithPrefix("[p]"))
+	var w = &legacyWriter{level: log4go.LevelInfo}
+	l.AddWriter("legacy", w)
+
+	l.Debugln("debug")
+	l.Infoln("hello", log4go.Any("id", 1))
+
+	if len(w.messages) != 1 {
+		t.Fatalf("writer 收到的日志错误: %q", w.messages)
+	}
+	var parts = strings.Split(w.messages[0], "|")
+	if _, err := time.ParseInLocation("2006/01/02 15:04:05.000000", parts[2], time.Local); err != nil {
+		t.Fatal(err)
+	}
+	if parts[0] != "svc" || parts[1] != "[p]" || parts[3] != "INFO" || parts[4] != "log_test.go" || parts[5] != "hello id=1\n" {
+		t.Fatalf("writer 收到的日志错误: %q", w.messages)
+	}
+
+	var r = log4go.NewMessageRecord("svc", "", "", parts[2], log4go.LevelInfo, "main.go", 1, "hello\n")
+	if r.Time.Format("2006/01/02 15:04:05.000000") != parts[2] || r.Service != "svc" || r.Line != 1 {
+		t.Fatalf("日志记录错误: %+v", r)
+	}
+}
+
+// blockingWriter 在 release 关闭之前阻塞写入
+type blockingWriter struct {
+	memoryWriter
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (this *blockingWriter) WriteRecord(r *log4go.Record) {
+	select {
+	case this.writing <- struct{}{}:
+	default:
+	}
+	<-this.release
+	this.memoryWriter.WriteRecord(r)
+}
+
+func TestLogger_RemoveWriterWhileLogging(t *testing.T) {
+	var l = log4go.New()
+	var a = &memoryWriter{}
+	var b = &memoryWriter{}
+	l.AddWriter("a", a)
+	l.AddWriter("b", b)
+
+	// Hook 中写日志的同时另一个 goroutine 正在移除 Writer
+	var fired int32
+	l.AddHook(nil, log4go.HookFunc(func(r *log4go.Record) {
+		if !atomic.CompareAndSwapInt32(&fired, 0, 1) {
+			return
+		}
+		var removing = make(chan struct{})
+		go func() {
+			close(removing)
+			l.RemoveWriter("b")
+		}()
+		<-removing
+		time.Sleep(50 * time.Millisecond)
+		l.Infoln("nested")
+	}))
+
+	var done = make(chan struct{})
+	go func() {
+		l.Infoln("outer")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Hook 中写日志时移除 Writer 发生死锁")
+	}
+
+	if records := a.Records(); len(records) != 2 {
+		t.Fatalf("a 收到的日志记录错误: %v", records)
+	}
+	for i := 0; i < 100 && !b.Closed(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !b.Closed() || l.Writer("b") != nil {
+		t.Fatal("b 应该被移除并关闭")
+	}
+}
+
+func TestLogger_RemoveWriterWaitsForWrites(t *testing.T) {
+	var l = log4go.New()
+	var w = &blockingWriter{writing: make(chan struct{}, 1), release: make(chan struct{})}
+	l.AddWriter("blocking", w)
+
+	go l.Infoln("hello")
+	<-w.writing
+
+	var removed = make(chan struct{})
+	go func() {
+		l.RemoveWriter("blocking")
+		close(removed)
+	}()
+
+	// 正在写入时不能关闭
+	time.Sleep(50 * time.Millisecond)
+	if w.Closed() {
+		t.Fatal("Writer 在写入完成之前被关闭")
+	}
+	// 移除之后的日志不再交给该 Writer，也不会被阻塞
+	l.Infoln("after")
+
+	close(w.release)
+	select {
+	case <-removed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RemoveWriter 没有返回")
+	}
+	if records := w.Records(); !w.Closed() || len(records) != 1 || records[0].Message != "hello\n" {
+		t.Fatalf("Writer 状态错误: %v %v", w.Closed(), records)
+	}
+}
@@ -2,30 +2,36 @@ package log4go
 
 import (
 	"errors"
-	"fmt"
 	"github.com/smartwalle/mail4go"
+	"sync"
+	"sync/atomic"
 )
 
 type MailWriter struct {
-	level   Level
-	config  *mail4go.MailConfig
-	subject string
-	from    string
-	to      []string
+	level     int32
+	config    *mail4go.MailConfig
+	subject   string
+	from      string
+	to        []string
+	mu        sync.Mutex
+	formatter Formatter
+	observed
 }
 
 func NewMailWriter(level Level) *MailWriter {
 	var mw = &MailWriter{}
-	mw.level = level
+	mw.level = int32(level)
+	mw.formatter = NewTextFormatter()
+	mw.setObservedName("mail")
 	return mw
 }
 
 func (this *MailWriter) SetLevel(level Level) {
-	this.level = level
+	atomic.StoreInt32(&this.level, int32(level))
 }
 
 func (this *MailWriter) GetLevel() Level {
-	return this.level
+	return this.Level()
 }
 
 func (this *MailWriter) SetMailConfig(config *mail4go.MailConfig) {
@@ -66,11 +72,15 @@ func (this *MailWriter) Write(p []byte) (n int, err error) {
 	}
 
 	if this.config == nil {
-		return -1, errors.New("邮件配置信息为空")
+		err = errors.New("邮件配置信息为空")
+		this.observeMail(err)
+		return -1, err
 	}
 
 	if len(this.to) == 0 {
-		return -1, errors.New("收件人信息不能为空")
+		err = errors.New("收件人信息不能为空")
+		this.observeMail(err)
+		return -1, err
 	}
 
 	var subject = this.GetSubject()
@@ -85,6 +95,7 @@ func (this *MailWriter) Write(p []byte) (n int, err error) {
 	}
 
 	err = mail4go.SendWithConfig(this.config, mail)
+	this.observeMail(err)
 	return 0, err
 }
 
@@ -93,9 +104,28 @@ func (this *MailWriter) Close() error {
 }
 
 func (this *MailWriter) Level() Level {
-	return this.level
+	return Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *MailWriter) SetFormatter(f Formatter) {
+	if f == nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.formatter = f
+}
+
+func (this *MailWriter) Formatter() Formatter {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.formatter
 }
 
 func (this *MailWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
-	fmt.Fprintf(this, "%s%s%s%s %s %s:%d %s", service, instance, prefix, logTime, LevelNames[level], file, line, msg)
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *MailWriter) WriteRecord(r *Record) {
+	this.Write(this.Formatter().Format(r))
 }
@@ -0,0 +1,468 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	kNetBufferSize   = 1024
+	kNetMinBackoff   = 100 * time.Millisecond
+	kNetMaxBackoff   = 30 * time.Second
+	kNetDialTimeout  = 5 * time.Second
+	kNetWriteTimeout = 5 * time.Second
+	kSpillFile       = "log4go.spill"
+	kSpillMaxSize    = 100 * 1024 * 1024
+)
+
+var errNotConnected = errors.New("连接已断开")
+
+type NetWriterOption interface {
+	Apply(*NetWriter)
+}
+
+type nwOptionFunc func(*NetWriter)
+
+func (f nwOptionFunc) Apply(w *NetWriter) {
+	f(w)
+}
+
+// WithNetBufferSize 设置内存中最多缓存的日志条数，默认为 1024
+func WithNetBufferSize(n int) NetWriterOption {
+	return nwOptionFunc(func(w *NetWriter) {
+		if n > 0 {
+			w.bufferSize = n
+		}
+	})
+}
+
+// WithNetBackoff 设置重新连接的等待时间，从 min 开始每次失败后加倍，最大为 max
+func WithNetBackoff(min, max time.Duration) NetWriterOption {
+	return nwOptionFunc(func(w *NetWriter) {
+		if min > 0 {
+			w.minBackoff = min
+		}
+		if max >= w.minBackoff {
+			w.maxBackoff = max
+		}
+	})
+}
+
+// WithNetSpillDir 内存中的缓存已满时将日志写入 dir 目录下的文件中，文件最大为 mb MB，
+// 不同的 NetWriter 应该使用不同的目录。程序重启之后会继续发送文件中未发送的日志
+func WithNetSpillDir(dir string, mb int64) NetWriterOption {
+	return nwOptionFunc(func(w *NetWriter) {
+		w.spillDir = dir
+		w.spillMaxSize = kSpillMaxSize
+		if mb > 0 {
+			w.spillMaxSize = mb * 1024 * 1024
+		}
+	})
+}
+
+// NetWriter 通过 TCP、UDP 或者 unix socket 发送日志，默认每条日志为一行 JSON。
+// 日志先放入内存中的缓存，由后台的 goroutine 发送，连接断开时按指数退避重新连接，
+// 缓存已满时写入磁盘（需要通过 WithNetSpillDir 开启）或者丢弃，丢弃的数量可以通过 Dropped 获取
+type NetWriter struct {
+	dropped      uint64
+	level        int32
+	closed       int32
+	network      string
+	addr         string
+	bufferSize   int
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	spillDir     string
+	spillMaxSize int64
+	mu           sync.Mutex
+	formatter    Formatter
+	queue        chan queuedMessage
+	spill        *spillQueue
+	wake         chan struct{}
+	stop         chan struct{}
+	done         chan struct{}
+	stopOnce     sync.Once
+	conn         net.Conn
+	observed
+}
+
+// queuedMessage 为等待后台 goroutine 发送的日志
+type queuedMessage struct {
+	level Level
+	data  []byte
+}
+
+func NewNetWriter(level Level, network, addr string, opts ...NetWriterOption) *NetWriter {
+	var nw = &NetWriter{}
+	nw.level = int32(level)
+	nw.network = network
+	nw.addr = addr
+	nw.bufferSize = kNetBufferSize
+	nw.minBackoff = kNetMinBackoff
+	nw.maxBackoff = kNetMaxBackoff
+	nw.formatter = NewJSONFormatter()
+	nw.setObservedName("net")
+	for _, opt := range opts {
+		opt.Apply(nw)
+	}
+
+	if nw.spillDir != "" {
+		var err error
+		if nw.spill, err = newSpillQueue(nw.spillDir, nw.spillMaxSize); err != nil {
+			return nil
+		}
+	}
+	nw.queue = make(chan queuedMessage, nw.bufferSize)
+	nw.wake = make(chan struct{}, 1)
+	nw.stop = make(chan struct{})
+	nw.done = make(chan struct{})
+
+	go nw.run()
+	return nw
+}
+
+// Write 将 p 放入缓存，不会等待发送完成
+func (this *NetWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var data = make([]byte, len(p))
+	copy(data, p)
+	this.enqueue(queuedMessage{level: this.Level(), data: data})
+	return len(p), nil
+}
+
+// Close 停止后台的 goroutine，已经连接时尝试发送缓存中剩余的日志，未能发送的日志写入磁盘或者丢弃
+func (this *NetWriter) Close() error {
+	this.stopOnce.Do(func() {
+		atomic.StoreInt32(&this.closed, 1)
+		close(this.stop)
+	})
+	<-this.done
+	return nil
+}
+
+func (this *NetWriter) Level() Level {
+	return Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *NetWriter) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *NetWriter) SetFormatter(f Formatter) {
+	if f == nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.formatter = f
+}
+
+func (this *NetWriter) Formatter() Formatter {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.formatter
+}
+
+func (this *NetWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *NetWriter) WriteRecord(r *Record) {
+	this.enqueue(queuedMessage{level: r.Level, data: this.Formatter().Format(r)})
+}
+
+// Dropped 返回因为缓存已满或者已经关闭而丢弃的日志数量
+func (this *NetWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *NetWriter) enqueue(m queuedMessage) {
+	if atomic.LoadInt32(&this.closed) == 1 {
+		this.drop(m)
+		return
+	}
+
+	// 磁盘中有未发送的日志时，新的日志也写入磁盘，保证发送的顺序
+	if this.spill == nil || this.spill.len() == 0 {
+		select {
+		case this.queue <- m:
+			return
+		default:
+		}
+	}
+	this.spillOrDrop(m)
+}
+
+func (this *NetWriter) spillOrDrop(m queuedMessage) {
+	if this.spill != nil && this.spill.push(m.data) {
+		select {
+		case this.wake <- struct{}{}:
+		default:
+		}
+		return
+	}
+	this.drop(m)
+}
+
+func (this *NetWriter) drop(m queuedMessage) {
+	atomic.AddUint64(&this.dropped, 1)
+	this.observeDrop(m.level)
+}
+
+func (this *NetWriter) run() {
+	defer close(this.done)
+
+	var pending *queuedMessage
+	var backoff = this.minBackoff
+	for {
+		if this.conn == nil {
+			conn, err := net.DialTimeout(this.network, this.addr, kNetDialTimeout)
+			if err != nil {
+				this.observeWrite(0, err)
+				if !this.sleep(backoff) {
+					this.shutdown(pending)
+					return
+				}
+				if backoff *= 2; backoff > this.maxBackoff {
+					backoff = this.maxBackoff
+				}
+				continue
+			}
+			this.conn = conn
+			backoff = this.minBackoff
+		}
+
+		if pending == nil {
+			select {
+			case m := <-this.queue:
+				pending = &m
+			case <-this.stop:
+				this.shutdown(nil)
+				return
+			default:
+				// 内存中的日志发送完之后再发送磁盘中的日志
+				if this.spill != nil && this.spill.len() > 0 {
+					if err := this.spill.drain(this.send); err != nil {
+						if _, ok := err.(*spillError); !ok {
+							this.closeConn()
+							continue
+						}
+						// 本地磁盘出错时保持连接，等待一段时间之后再重试
+						this.observeWrite(0, err)
+						if !this.sleep(this.minBackoff) {
+							this.shutdown(nil)
+							return
+						}
+					}
+					continue
+				}
+				select {
+				case m := <-this.queue:
+					pending = &m
+				case <-this.wake:
+					continue
+				case <-this.stop:
+					this.shutdown(nil)
+					return
+				}
+			}
+		}
+
+		if err := this.send(pending.data); err != nil {
+			this.closeConn()
+			continue
+		}
+		pending = nil
+	}
+}
+
+func (this *NetWriter) sleep(d time.Duration) bool {
+	var timer = time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-this.stop:
+		return false
+	}
+}
+
+func (this *NetWriter) send(data []byte) error {
+	if this.conn == nil {
+		return errNotConnected
+	}
+	// 流式连接中每条日志占一行
+	if isStreamNetwork(this.network) && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data[:len(data):len(data)], '\n')
+	}
+	this.conn.SetWriteDeadline(time.Now().Add(kNetWriteTimeout))
+	n, err := this.conn.Write(data)
+	this.observeWrite(n, err)
+	return err
+}
+
+func (this *NetWriter) closeConn() {
+	if this.conn != nil {
+		this.conn.Close()
+		this.conn = nil
+	}
+}
+
+// shutdown 在已经连接时发送剩余的日志，发送失败或者未连接时写入磁盘或者丢弃
+func (this *NetWriter) shutdown(pending *queuedMessage) {
+	var rest []queuedMessage
+	if pending != nil {
+		rest = append(rest, *pending)
+	}
+loop:
+	for {
+		select {
+		case m := <-this.queue:
+			rest = append(rest, m)
+		default:
+			break loop
+		}
+	}
+
+	for i, m := range rest {
+		if this.conn == nil || this.send(m.data) != nil {
+			this.closeConn()
+			for _, m := range rest[i:] {
+				this.spillOrDrop(m)
+			}
+			break
+		}
+	}
+	this.closeConn()
+}
+
+// spillQueue 将日志保存在磁盘文件中，每条日志之前为 4 字节的长度
+type spillQueue struct {
+	mu   sync.Mutex
+	path string
+	max  int64
+	size int64
+}
+
+func newSpillQueue(dir string, max int64) (*spillQueue, error) {
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return nil, err
+	}
+	var q = &spillQueue{}
+	q.path = filepath.Join(dir, kSpillFile)
+	q.max = max
+	if info, err := os.Stat(q.path); err == nil {
+		q.size = info.Size()
+	}
+	// 上次发送过程中退出的程序留下的文件
+	if data, err := ioutil.ReadFile(q.sendingPath()); err == nil {
+		q.restore(data)
+	}
+	return q, nil
+}
+
+func (this *spillQueue) sendingPath() string {
+	return this.path + ".sending"
+}
+
+func (this *spillQueue) len() int64 {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.size
+}
+
+func (this *spillQueue) push(data []byte) bool {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	var size = int64(4 + len(data))
+	if this.size+size > this.max {
+		return false
+	}
+
+	file, err := os.OpenFile(this.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	var buf = make([]byte, 4, size)
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+	if _, err = file.Write(buf); err != nil {
+		return false
+	}
+	this.size += size
+	return true
+}
+
+// spillError 为读写磁盘文件时的错误，与发送日志时的错误不同，不需要断开连接
+type spillError struct {
+	err error
+}
+
+func (this *spillError) Error() string {
+	return "读写缓存文件出错: " + this.err.Error()
+}
+
+// drain 依次发送文件中的日志，发送失败时将未发送的日志放回文件的开头，读写文件出错时返回 *spillError
+func (this *spillQueue) drain(send func([]byte) error) error {
+	this.mu.Lock()
+	if this.size == 0 {
+		this.mu.Unlock()
+		return nil
+	}
+	if err := os.Rename(this.path, this.sendingPath()); err != nil {
+		this.mu.Unlock()
+		return &spillError{err: err}
+	}
+	this.size = 0
+	this.mu.Unlock()
+
+	data, err := ioutil.ReadFile(this.sendingPath())
+	if err != nil {
+		return &spillError{err: err}
+	}
+
+	var offset = 0
+	for offset+4 <= len(data) {
+		var end = offset + 4 + int(binary.BigEndian.Uint32(data[offset:]))
+		if end > len(data) {
+			// 不完整的日志
+			break
+		}
+		if err = send(data[offset+4 : end]); err != nil {
+			this.restore(data[offset:])
+			return err
+		}
+		offset = end
+	}
+	os.Remove(this.sendingPath())
+	return nil
+}
+
+// restore 将 data 放在文件的开头
+func (this *spillQueue) restore(data []byte) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	var current, _ = ioutil.ReadFile(this.path)
+	var content = make([]byte, 0, len(data)+len(current))
+	content = append(content, data...)
+	content = append(content, current...)
+	if err := ioutil.WriteFile(this.path, content, 0644); err != nil {
+		return
+	}
+	this.size = int64(len(content))
+	os.Remove(this.sendingPath())
+}
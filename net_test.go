@@ -0,0 +1,228 @@
+package log4go_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func readLines(t *testing.T, ln net.Listener, n int) []string {
+	var lines = make(chan string, n)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var scanner = bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	var result []string
+	for len(result) < n {
+		select {
+		case line := <-lines:
+			result = append(result, line)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("没有收到足够的日志: %v", result)
+		}
+	}
+	return result
+}
+
+func TestNetWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var nw = log4go.NewNetWriter(log4go.LevelTrace, "tcp", ln.Addr().String())
+	defer nw.Close()
+
+	var l = log4go.New()
+	l.AddWriter("net", nw)
+	l.Infoln("hello", log4go.Any("id", 1))
+	l.Warnln("world")
+
+	var lines = readLines(t, ln, 2)
+	var obj map[string]interface{}
+	if err = json.Unmarshal([]byte(lines[0]), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["msg"] != "hello" || obj["level"] != "info" || obj["id"] != float64(1) {
+		t.Fatalf("日志内容错误: %s", lines[0])
+	}
+}
+
+func TestNetWriter_Spill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 获取一个当前没有被监听的端口
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addr = ln.Addr().String()
+	ln.Close()
+
+	var nw = log4go.NewNetWriter(log4go.LevelTrace, "tcp", addr,
+		log4go.WithNetBufferSize(2), log4go.WithNetBackoff(10*time.Millisecond, 50*time.Millisecond), log4go.WithNetSpillDir(dir, 1))
+	defer nw.Close()
+	nw.SetFormatter(log4go.FormatterFunc(func(r *log4go.Record) []byte {
+		return []byte(r.Message)
+	}))
+
+	var messages = []string{"1", "2", "3", "4", "5"}
+	for _, msg := range messages {
+		nw.WriteRecord(&log4go.Record{Level: log4go.LevelInfo, Message: msg})
+	}
+
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var lines = readLines(t, ln, len(messages))
+	for i, line := range lines {
+		if line != messages[i] {
+			t.Fatalf("日志顺序错误: %v", lines)
+		}
+	}
+	if nw.Dropped() != 0 {
+		t.Fatalf("不应该丢弃日志: %d", nw.Dropped())
+	}
+}
+
+func TestNetWriter_Dropped(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addr = ln.Addr().String()
+	ln.Close()
+
+	var nw = log4go.NewNetWriter(log4go.LevelTrace, "tcp", addr, log4go.WithNetBufferSize(1), log4go.WithNetBackoff(time.Hour, time.Hour))
+	var l = log4go.New()
+	l.AddWriter("net", nw)
+	l.Infoln("1")
+	l.Infoln("2")
+	l.Infoln("3")
+
+	if nw.Dropped() != 2 {
+		t.Fatalf("丢弃的日志数量错误: %d", nw.Dropped())
+	}
+	if infos := l.WriterInfos(); len(infos) != 1 || infos[0].Dropped != 2 {
+		t.Fatalf("Writer 信息错误: %+v", infos)
+	}
+
+	// 关闭时未连接，缓存中的日志也被丢弃
+	nw.Close()
+	if nw.Dropped() != 3 {
+		t.Fatalf("丢弃的日志数量错误: %d", nw.Dropped())
+	}
+}
+
+func TestNetWriter_SpillError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addr = ln.Addr().String()
+	ln.Close()
+
+	var nw = log4go.NewNetWriter(log4go.LevelTrace, "tcp", addr,
+		log4go.WithNetBufferSize(2), log4go.WithNetBackoff(10*time.Millisecond, 50*time.Millisecond), log4go.WithNetSpillDir(dir, 1))
+	defer nw.Close()
+	nw.SetFormatter(log4go.FormatterFunc(func(r *log4go.Record) []byte {
+		return []byte(r.Message)
+	}))
+
+	for _, msg := range []string{"1", "2", "3", "4", "5"} {
+		nw.WriteRecord(&log4go.Record{Level: log4go.LevelInfo, Message: msg})
+	}
+
+	// 在发送中的文件的位置创建非空的目录，使发送磁盘中的日志时重命名文件失败
+	var block = filepath.Join(dir, "log4go.spill.sending")
+	if err = os.MkdirAll(filepath.Join(block, "block"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var accepted int32
+	var lines = make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			if atomic.AddInt32(&accepted, 1) > 1 {
+				conn.Close()
+				continue
+			}
+			go func() {
+				defer conn.Close()
+				var scanner = bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+
+	var received = make(map[string]bool)
+	var wait = func(n int) {
+		for len(received) < n {
+			select {
+			case line := <-lines:
+				received[line] = true
+			case <-time.After(5 * time.Second):
+				t.Fatalf("没有收到足够的日志: %v", received)
+			}
+		}
+	}
+
+	// 内存中的日志发送之后，发送磁盘中的日志时出错，等待几次重试
+	wait(2)
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt32(&accepted); n != 1 {
+		t.Fatalf("磁盘出错时不应该断开连接, 连接次数: %d", n)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "log4go.spill")); err != nil || info.Size() == 0 {
+		t.Fatalf("重命名失败时不应该丢失磁盘中的日志: %v", err)
+	}
+
+	nw.WriteRecord(&log4go.Record{Level: log4go.LevelInfo, Message: "6"})
+	os.RemoveAll(block)
+	wait(6)
+	if n := atomic.LoadInt32(&accepted); n != 1 {
+		t.Fatalf("磁盘出错时不应该断开连接, 连接次数: %d", n)
+	}
+	if nw.Dropped() != 0 {
+		t.Fatalf("不应该丢弃日志: %d", nw.Dropped())
+	}
+}
@@ -0,0 +1,99 @@
+package log4go
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Observer 接收日志处理过程中的统计信息，通过 SetObserver 设置，方法会被并发调用，应该尽快返回
+type Observer interface {
+	// ObserveRecord Logger 收到达到级别要求的日志记录时调用，sampled 为 false 表示被 Sampler 丢弃
+	ObserveRecord(logger string, level Level, sampled bool)
+
+	// ObserveDispatch 日志记录交给 Writer 处理之后调用，latency 为 Writer.WriteMessage 的耗时
+	ObserveDispatch(writer string, level Level, latency time.Duration)
+
+	// ObserveWrite StdWriter、FileWriter 写入数据之后调用
+	ObserveWrite(writer string, n int, err error)
+
+	// ObserveDrop Writer 在内部丢弃日志记录时调用，如缓冲区已满
+	ObserveDrop(writer string, level Level)
+
+	// ObserveRotate FileWriter 切分日志文件之后调用，file 为切分出来的文件
+	ObserveRotate(writer string, file string, err error)
+
+	// ObserveMail MailWriter 发送邮件之后调用
+	ObserveMail(writer string, err error)
+}
+
+// NopObserver 忽略所有的统计信息，可以嵌入到自定义的 Observer 中，只实现需要的方法
+type NopObserver struct {
+}
+
+func (NopObserver) ObserveRecord(logger string, level Level, sampled bool)            {}
+func (NopObserver) ObserveDispatch(writer string, level Level, latency time.Duration) {}
+func (NopObserver) ObserveWrite(writer string, n int, err error)                      {}
+func (NopObserver) ObserveDrop(writer string, level Level)                            {}
+func (NopObserver) ObserveRotate(writer string, file string, err error)               {}
+func (NopObserver) ObserveMail(writer string, err error)                              {}
+
+type observerHolder struct {
+	observer Observer
+}
+
+var sharedObserver atomic.Value
+
+// SetObserver 设置接收统计信息的 Observer，为 nil 时不再收集统计信息
+func SetObserver(o Observer) {
+	sharedObserver.Store(observerHolder{observer: o})
+}
+
+func getObserver() Observer {
+	if h, ok := sharedObserver.Load().(observerHolder); ok {
+		return h.observer
+	}
+	return nil
+}
+
+// observedWriter 由需要上报统计信息的内置 Writer 实现，添加到 Logger 时传入 Writer 的名称
+type observedWriter interface {
+	setObservedName(name string)
+}
+
+// observed 嵌入到内置的 Writer 中，保存 Writer 的名称并上报统计信息
+type observed struct {
+	name atomic.Value
+}
+
+func (this *observed) setObservedName(name string) {
+	this.name.Store(name)
+}
+
+func (this *observed) observedName() string {
+	var name, _ = this.name.Load().(string)
+	return name
+}
+
+func (this *observed) observeWrite(n int, err error) {
+	if o := getObserver(); o != nil {
+		o.ObserveWrite(this.observedName(), n, err)
+	}
+}
+
+func (this *observed) observeDrop(level Level) {
+	if o := getObserver(); o != nil {
+		o.ObserveDrop(this.observedName(), level)
+	}
+}
+
+func (this *observed) observeRotate(file string, err error) {
+	if o := getObserver(); o != nil {
+		o.ObserveRotate(this.observedName(), file, err)
+	}
+}
+
+func (this *observed) observeMail(err error) {
+	if o := getObserver(); o != nil {
+		o.ObserveMail(this.observedName(), err)
+	}
+}
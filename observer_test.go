@@ -0,0 +1,73 @@
+package log4go_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"github.com/smartwalle/log4go"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	// expvar 不允许重复发布相同的名称，使用 -count 多次运行时需要不同的名称
+	var name = fmt.Sprintf("log4go_test_%d", time.Now().UnixNano())
+	var o = log4go.PublishExpvar(name)
+	defer log4go.SetObserver(nil)
+	if expvar.Get(name) != o.Map() {
+		t.Fatal("发布到 expvar 失败")
+	}
+}
+
+func TestExpvarObserver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var o = log4go.NewExpvarObserver()
+	log4go.SetObserver(o)
+	defer log4go.SetObserver(nil)
+
+	var fw = log4go.NewFileWriter(log4go.LevelTrace, log4go.WithLogDir(dir), log4go.WithMaxSize(1))
+	defer fw.Close()
+
+	var l = log4go.New(log4go.WithSampler(log4go.NewCountSampler(time.Minute, 2, 0)))
+	l.AddWriter("file", fw)
+	l.AddWriter("memory", &memoryWriter{}, log4go.LevelRange(log4go.LevelWarning, log4go.LevelFatal))
+
+	var large = strings.Repeat("a", 600*1024)
+	l.Infoln(large)
+	l.Infoln(large)
+	l.Infoln(large)
+	l.Warnln("hello")
+
+	var stats struct {
+		Records    map[string]int64 `json:"records"`
+		SampledOut map[string]int64 `json:"sampled_out"`
+		Writers    map[string]struct {
+			Records   map[string]int64 `json:"records"`
+			Bytes     int64            `json:"bytes"`
+			Rotations int64            `json:"rotations"`
+			LatencyNs int64            `json:"latency_ns"`
+		} `json:"writers"`
+	}
+	if err = json.Unmarshal([]byte(o.Map().String()), &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Records["info"] != 3 || stats.Records["warning"] != 1 || stats.SampledOut["info"] != 1 {
+		t.Fatalf("日志数量统计错误: %+v", stats)
+	}
+	var file, memory = stats.Writers["file"], stats.Writers["memory"]
+	if file.Records["info"] != 2 || file.Records["warning"] != 1 || file.Rotations != 1 || file.Bytes <= 2*600*1024 || file.LatencyNs <= 0 {
+		t.Fatalf("file 统计错误: %+v", file)
+	}
+	if memory.Records["info"] != 0 || memory.Records["warning"] != 1 || memory.Bytes != 0 {
+		t.Fatalf("memory 统计错误: %+v", memory)
+	}
+}
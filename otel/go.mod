@@ -0,0 +1,17 @@
+module github.com/smartwalle/log4go/otel
+
+go 1.20
+
+require (
+	github.com/smartwalle/log4go v0.0.0
+	go.opentelemetry.io/otel v1.19.0
+	go.opentelemetry.io/otel/trace v1.19.0
+)
+
+require (
+	github.com/mattn/go-isatty v0.0.7 // indirect
+	github.com/smartwalle/mail4go v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/smartwalle/log4go => ../
@@ -0,0 +1,140 @@
+// Package otel 将 OpenTelemetry 的 trace 信息关联到 log4go 的日志记录中。
+//
+// 单独作为一个 module，避免 log4go 引入 OpenTelemetry 的依赖：
+//
+//	otel.Register()
+//	log4go.InfoCtx(ctx, "hello") // 日志记录中包含 trace_id 和 span_id
+//
+//	// 同时将日志记录作为 span 的 event
+//	log4go.AddWriter("span", otel.NewSpanEventWriter(log4go.LevelInfo))
+package otel
+
+import (
+	"context"
+	"fmt"
+	"github.com/smartwalle/log4go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	TraceIdKey = "trace_id"
+	SpanIdKey  = "span_id"
+)
+
+var once sync.Once
+
+// Register 注册 Extractor，重复调用只会注册一次
+func Register() {
+	once.Do(func() {
+		log4go.RegisterContextExtractor(Extractor)
+	})
+}
+
+// Extractor 从 ctx 中提取当前 span 的 trace_id 和 span_id
+func Extractor(ctx context.Context) []log4go.Field {
+	var sc = trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []log4go.Field{
+		log4go.Any(TraceIdKey, sc.TraceID().String()),
+		log4go.Any(SpanIdKey, sc.SpanID().String()),
+	}
+}
+
+// SpanEventWriter 将日志记录添加为 ctx 中当前 span 的 event，没有 ctx 或者 span 未在记录时忽略该日志
+type SpanEventWriter struct {
+	level       int32
+	errorStatus int32
+}
+
+func NewSpanEventWriter(level log4go.Level) *SpanEventWriter {
+	var sw = &SpanEventWriter{}
+	sw.level = int32(level)
+	return sw
+}
+
+// SetErrorStatus 设置是否在写入 Error 及以上级别的日志时将 span 的状态设置为 Error
+func (this *SpanEventWriter) SetErrorStatus(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&this.errorStatus, v)
+}
+
+func (this *SpanEventWriter) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+func (this *SpanEventWriter) Close() error {
+	return nil
+}
+
+func (this *SpanEventWriter) Level() log4go.Level {
+	return log4go.Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *SpanEventWriter) SetLevel(level log4go.Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *SpanEventWriter) WriteMessage(service, instance, prefix, logTime string, level log4go.Level, file string, line int, msg string) {
+	this.WriteRecord(log4go.NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *SpanEventWriter) WriteRecord(r *log4go.Record) {
+	if r.Context == nil {
+		return
+	}
+	var span = trace.SpanFromContext(r.Context)
+	if !span.IsRecording() {
+		return
+	}
+
+	var msg = strings.TrimRight(r.Message, "\n")
+	var attrs = make([]attribute.KeyValue, 0, 4+len(r.Fields))
+	attrs = append(attrs,
+		attribute.String("log.severity", r.Level.String()),
+		attribute.String("log.message", msg),
+		attribute.String("code.filepath", r.File),
+		attribute.Int("code.lineno", r.Line),
+	)
+	for _, f := range r.Fields {
+		if f.Key == TraceIdKey || f.Key == SpanIdKey {
+			continue
+		}
+		attrs = append(attrs, fieldAttribute(f))
+	}
+
+	span.AddEvent("log", trace.WithTimestamp(r.Time), trace.WithAttributes(attrs...))
+
+	if atomic.LoadInt32(&this.errorStatus) == 1 && r.Level >= log4go.LevelError {
+		span.SetStatus(codes.Error, msg)
+	}
+}
+
+func fieldAttribute(f log4go.Field) attribute.KeyValue {
+	switch v := f.Value.(type) {
+	case string:
+		return attribute.String(f.Key, v)
+	case bool:
+		return attribute.Bool(f.Key, v)
+	case int:
+		return attribute.Int(f.Key, v)
+	case int64:
+		return attribute.Int64(f.Key, v)
+	case float64:
+		return attribute.Float64(f.Key, v)
+	case error:
+		return attribute.String(f.Key, v.Error())
+	case fmt.Stringer:
+		return attribute.String(f.Key, v.String())
+	}
+	return attribute.String(f.Key, fmt.Sprint(f.Value))
+}
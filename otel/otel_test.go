@@ -0,0 +1,162 @@
+package otel_test
+
+import (
+	"context"
+	"github.com/smartwalle/log4go"
+	"github.com/smartwalle/log4go/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExtractor(t *testing.T) {
+	if fields := otel.Extractor(context.Background()); len(fields) != 0 {
+		t.Fatalf("没有 span 时不应该返回字段: %v", fields)
+	}
+
+	var sc = trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+	})
+	var ctx = trace.ContextWithSpanContext(context.Background(), sc)
+
+	var fields = otel.Extractor(ctx)
+	if len(fields) != 2 ||
+		fields[0] != log4go.Any(otel.TraceIdKey, sc.TraceID().String()) ||
+		fields[1] != log4go.Any(otel.SpanIdKey, sc.SpanID().String()) {
+		t.Fatalf("提取的字段错误: %v", fields)
+	}
+}
+
+// spanRecorder 为在内存中记录 event 及状态的 span
+type spanRecorder struct {
+	trace.Span
+	mu     sync.Mutex
+	events []spanEvent
+	code   codes.Code
+	desc   string
+}
+
+type spanEvent struct {
+	name  string
+	time  time.Time
+	attrs map[attribute.Key]attribute.Value
+}
+
+func newSpanRecorder() *spanRecorder {
+	return &spanRecorder{Span: trace.SpanFromContext(context.Background())}
+}
+
+func (this *spanRecorder) IsRecording() bool {
+	return true
+}
+
+func (this *spanRecorder) AddEvent(name string, opts ...trace.EventOption) {
+	var cfg = trace.NewEventConfig(opts...)
+	var event = spanEvent{name: name, time: cfg.Timestamp(), attrs: make(map[attribute.Key]attribute.Value)}
+	for _, attr := range cfg.Attributes() {
+		event.attrs[attr.Key] = attr.Value
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.events = append(this.events, event)
+}
+
+func (this *spanRecorder) SetStatus(code codes.Code, desc string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.code = code
+	this.desc = desc
+}
+
+func (this *spanRecorder) Events() []spanEvent {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return append([]spanEvent(nil), this.events...)
+}
+
+func (this *spanRecorder) Status() (codes.Code, string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.code, this.desc
+}
+
+func TestSpanEventWriter(t *testing.T) {
+	var span = newSpanRecorder()
+	var ctx = trace.ContextWithSpan(context.Background(), span)
+
+	var l = log4go.New()
+	var w = otel.NewSpanEventWriter(log4go.LevelInfo)
+	l.AddWriter("span", w)
+
+	l.DebugCtx(ctx, "debug")
+	l.InfoCtx(ctx, "hello", log4go.Any("user", "bob"), log4go.Any("count", 3))
+	// 没有 ctx 或者 span 未在记录时忽略
+	l.Infoln("no context")
+	l.InfoCtx(context.Background(), "no span")
+
+	var events = span.Events()
+	if len(events) != 1 || events[0].name != "log" || events[0].time.IsZero() {
+		t.Fatalf("span 的 event 错误: %v", events)
+	}
+	var attrs = events[0].attrs
+	if attrs["log.severity"].AsString() != "INFO" || attrs["log.message"].AsString() != "hello" || attrs["user"].AsString() != "bob" || attrs["count"].AsInt64() != 3 {
+		t.Fatalf("event 的属性错误: %v", attrs)
+	}
+	if !strings.HasSuffix(attrs["code.filepath"].AsString(), "otel_test.go") || attrs["code.lineno"].AsInt64() <= 0 {
+		t.Fatalf("event 的属性错误: %v", attrs)
+	}
+	if code, _ := span.Status(); code != codes.Unset {
+		t.Fatalf("未开启 SetErrorStatus 时不应该设置 span 的状态: %v", code)
+	}
+
+	l.WriteMessageContext(ctx, 1, log4go.LevelError, "failed\n")
+	if code, _ := span.Status(); code != codes.Unset {
+		t.Fatalf("未开启 SetErrorStatus 时不应该设置 span 的状态: %v", code)
+	}
+
+	w.SetErrorStatus(true)
+	l.WarnCtx(ctx, "warn")
+	if code, _ := span.Status(); code != codes.Unset {
+		t.Fatalf("Error 以下级别不应该设置 span 的状态: %v", code)
+	}
+	l.WriteMessageContext(ctx, 1, log4go.LevelError, "failed again\n")
+	if code, desc := span.Status(); code != codes.Error || desc != "failed again" {
+		t.Fatalf("span 的状态错误: %v %s", code, desc)
+	}
+	if events = span.Events(); len(events) != 4 {
+		t.Fatalf("span 的 event 数量错误: %d", len(events))
+	}
+}
+
+func TestSpanEventWriter_Concurrent(t *testing.T) {
+	var span = newSpanRecorder()
+	var ctx = trace.ContextWithSpan(context.Background(), span)
+	var w = otel.NewSpanEventWriter(log4go.LevelTrace)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			w.SetErrorStatus(i%2 == 0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			w.WriteRecord(&log4go.Record{Level: log4go.LevelError, Message: "failed", Context: ctx})
+		}
+	}()
+	wg.Wait()
+
+	if events := span.Events(); len(events) != 100 {
+		t.Fatalf("span 的 event 数量错误: %d", len(events))
+	}
+}
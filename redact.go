@@ -0,0 +1,277 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	kRedactMask = "******"
+)
+
+var (
+	kCardPattern   = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	kBearerPattern = regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	kEmailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	kRedactKeys = []string{"password", "passwd", "secret", "token", "authorization"}
+)
+
+type RedactorOption interface {
+	Apply(*Redactor)
+}
+
+type rdOptionFunc func(*Redactor)
+
+func (f rdOptionFunc) Apply(r *Redactor) {
+	f(r)
+}
+
+// WithRedactMask 设置替换敏感信息的字符串，默认为 ******
+func WithRedactMask(mask string) RedactorOption {
+	return rdOptionFunc(func(r *Redactor) {
+		r.mask = mask
+	})
+}
+
+// WithRedactPattern 添加需要隐藏的内容，与 re 匹配的内容都会被替换
+func WithRedactPattern(re *regexp.Regexp) RedactorOption {
+	return rdOptionFunc(func(r *Redactor) {
+		if re == nil {
+			return
+		}
+		r.patterns = append(r.patterns, func(s, mask string) string {
+			return re.ReplaceAllLiteralString(s, mask)
+		})
+	})
+}
+
+// WithRedactKeys 添加需要隐藏值的字段名称，不区分大小写
+func WithRedactKeys(keys ...string) RedactorOption {
+	return rdOptionFunc(func(r *Redactor) {
+		for _, key := range keys {
+			r.keys[strings.ToLower(key)] = struct{}{}
+		}
+	})
+}
+
+// WithoutDefaultRedaction 不使用内置的银行卡号、Bearer Token、邮箱地址规则和字段名称
+func WithoutDefaultRedaction() RedactorOption {
+	return rdOptionFunc(func(r *Redactor) {
+		r.patterns = nil
+		r.keys = make(map[string]struct{})
+	})
+}
+
+// Redactor 在日志记录交给 Writer 处理之前隐藏其中的敏感信息，
+// 默认隐藏通过 Luhn 校验的银行卡号、Bearer Token、邮箱地址，以及 password、authorization 等字段的值，
+// map、struct 等类型的字段值会检查其中的键名及内容，fmt.Stringer 及 []byte 会检查其字符串形式
+type Redactor struct {
+	mask     string
+	patterns []func(s, mask string) string
+	keys     map[string]struct{}
+}
+
+func NewRedactor(opts ...RedactorOption) *Redactor {
+	var rd = &Redactor{}
+	rd.mask = kRedactMask
+	rd.patterns = []func(s, mask string) string{redactCards, redactBearer, redactEmails}
+	rd.keys = make(map[string]struct{})
+	for _, key := range kRedactKeys {
+		rd.keys[key] = struct{}{}
+	}
+	for _, opt := range opts {
+		opt.Apply(rd)
+	}
+	return rd
+}
+
+// Redact 隐藏日志消息及字段中的敏感信息，字段会复制一份之后再修改，不会影响 Context 中保存的字段
+func (this *Redactor) Redact(r *Record) {
+	r.Message = this.RedactString(r.Message)
+
+	var fields []Field
+	for i, f := range r.Fields {
+		var value, changed = this.redactField(f)
+		if !changed {
+			continue
+		}
+		if fields == nil {
+			fields = make([]Field, len(r.Fields))
+			copy(fields, r.Fields)
+		}
+		fields[i].Value = value
+	}
+	if fields != nil {
+		r.Fields = fields
+	}
+}
+
+// RedactString 隐藏 s 中与规则匹配的内容
+func (this *Redactor) RedactString(s string) string {
+	for _, fn := range this.patterns {
+		s = fn(s, this.mask)
+	}
+	return s
+}
+
+func (this *Redactor) redactKey(key string) bool {
+	// 字段名称可能带有分组前缀，如 request.password
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		key = key[i+1:]
+	}
+	_, ok := this.keys[strings.ToLower(key)]
+	return ok
+}
+
+func (this *Redactor) redactField(f Field) (interface{}, bool) {
+	if f.Value == nil {
+		return nil, false
+	}
+	if this.redactKey(f.Key) {
+		return this.mask, true
+	}
+	return this.redactValue(f.Value)
+}
+
+// redactValue 隐藏字段值中的敏感信息，值被修改时返回新的值，不会修改原来的值
+func (this *Redactor) redactValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return value, false
+	case string:
+		var s = this.RedactString(v)
+		return s, s != v
+	case *ErrorDetail:
+		if v == nil {
+			return value, false
+		}
+		var detail = *v
+		var changed bool
+		detail.Message = this.RedactString(v.Message)
+		changed = detail.Message != v.Message
+		detail.Chain = make([]ErrorCause, len(v.Chain))
+		for i, cause := range v.Chain {
+			cause.Message = this.RedactString(cause.Message)
+			changed = changed || cause.Message != v.Chain[i].Message
+			detail.Chain[i] = cause
+		}
+		return &detail, changed
+	case json.Marshaler:
+		return this.redactJSON(value)
+	case error:
+		var s = this.RedactString(v.Error())
+		return s, s != v.Error()
+	case fmt.Stringer:
+		var s = v.String()
+		var rs = this.RedactString(s)
+		return rs, rs != s
+	case []byte:
+		var s = this.RedactString(string(v))
+		return s, s != string(v)
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		return this.redactJSON(value)
+	}
+	var s = fieldString(value)
+	var rs = this.RedactString(s)
+	return rs, rs != s
+}
+
+// redactJSON 将 map、struct 等类型的值转换为 JSON 之后再检查其中的字段名称及内容，
+// 和 JSON 格式输出的内容保持一致，有修改时返回 map[string]interface{} 等通用类型的值
+func (this *Redactor) redactJSON(value interface{}) (interface{}, bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		var s = fieldString(value)
+		var rs = this.RedactString(s)
+		return rs, rs != s
+	}
+
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var node interface{}
+	if err = decoder.Decode(&node); err != nil {
+		return value, false
+	}
+	if node, changed := this.redactNode(node); changed {
+		return node, true
+	}
+	return value, false
+}
+
+func (this *Redactor) redactNode(node interface{}) (interface{}, bool) {
+	switch v := node.(type) {
+	case string:
+		var s = this.RedactString(v)
+		return s, s != v
+	case map[string]interface{}:
+		var changed bool
+		for key, item := range v {
+			if this.redactKey(key) {
+				v[key] = this.mask
+				changed = true
+				continue
+			}
+			if item, ok := this.redactNode(item); ok {
+				v[key] = item
+				changed = true
+			}
+		}
+		return v, changed
+	case []interface{}:
+		var changed bool
+		for i, item := range v {
+			if item, ok := this.redactNode(item); ok {
+				v[i] = item
+				changed = true
+			}
+		}
+		return v, changed
+	}
+	return node, false
+}
+
+func redactCards(s, mask string) string {
+	return kCardPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if luhn(m) {
+			return mask
+		}
+		return m
+	})
+}
+
+func redactBearer(s, mask string) string {
+	return kBearerPattern.ReplaceAllString(s, "${1}"+strings.Replace(mask, "$", "$$", -1))
+}
+
+func redactEmails(s, mask string) string {
+	return kEmailPattern.ReplaceAllLiteralString(s, mask)
+}
+
+// luhn 检查 s 中的数字是否能通过 Luhn 校验，忽略空格和 -
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		var c = s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		var d = int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
@@ -0,0 +1,128 @@
+package log4go_test
+
+import (
+	"context"
+	"errors"
+	"github.com/smartwalle/log4go"
+	"regexp"
+	"testing"
+)
+
+func TestRedactor_RedactString(t *testing.T) {
+	var rd = log4go.NewRedactor(log4go.WithRedactPattern(regexp.MustCompile(`sk-[a-z0-9]+`)))
+
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{"card 4111 1111 1111 1111 paid", "card ****** paid"},
+		{"card 4111-1111-1111-1112 paid", "card 4111-1111-1111-1112 paid"},
+		{"order 1234567890123", "order 1234567890123"},
+		{"Authorization: Bearer abc.def-ghi==", "Authorization: Bearer ******"},
+		{"mail to user.name+tag@example.com now", "mail to ****** now"},
+		{"key sk-abc123", "key ******"},
+	}
+	for _, test := range tests {
+		if out := rd.RedactString(test.in); out != test.out {
+			t.Errorf("%q 应该为 %q, 实际为 %q", test.in, test.out, out)
+		}
+	}
+}
+
+func TestLogger_SetRedactor(t *testing.T) {
+	var l = log4go.New(log4go.WithRedactor(log4go.NewRedactor(log4go.WithRedactMask("[hidden]"), log4go.WithRedactKeys("session"))))
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	var ctxFields = []log4go.Field{log4go.Any("password", "123456")}
+	var ctx = log4go.ContextWithFields(context.Background(), ctxFields...)
+
+	l.GetLogger("child").InfoCtx(ctx, "login bob@example.com",
+		log4go.Any("Session", "s-1"),
+		log4go.Any("req.authorization", "Basic xyz"),
+		log4go.Any("count", 1),
+		log4go.Err(errors.New("no user bob@example.com")))
+
+	var records = w.Records()
+	if len(records) != 1 {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+	var r = records[0]
+	if r.Message != "login [hidden]\n" {
+		t.Fatalf("日志消息错误: %q", r.Message)
+	}
+	if len(r.Fields) != 5 || r.Fields[0].Value != "[hidden]" || r.Fields[1].Value != "[hidden]" || r.Fields[2].Value != "[hidden]" || r.Fields[3].Value != 1 {
+		t.Fatalf("日志字段错误: %v", r.Fields)
+	}
+	if detail, ok := r.Fields[4].Value.(*log4go.ErrorDetail); !ok || detail.Message != "no user [hidden]" {
+		t.Fatalf("错误信息错误: %v", r.Fields[4])
+	}
+	if ctxFields[0].Value != "123456" {
+		t.Fatal("不应该修改 Context 中保存的字段")
+	}
+}
+
+type redactUser struct {
+	Name  string
+	Email string
+}
+
+func (this redactUser) String() string {
+	return this.Name + " <" + this.Email + ">"
+}
+
+type redactAccount struct {
+	Name     string            `json:"name"`
+	Password string            `json:"password"`
+	Headers  map[string]string `json:"headers"`
+	Emails   []string          `json:"emails"`
+}
+
+func TestRedactor_FieldValues(t *testing.T) {
+	var rd = log4go.NewRedactor()
+
+	var headers = map[string]string{"Authorization": "Bearer abc.def", "X-Request-Id": "1"}
+	var account = &redactAccount{
+		Name:     "bob",
+		Password: "hunter2",
+		Headers:  map[string]string{"Cookie": "sid=1", "X-Token": "bearer abc.def"},
+		Emails:   []string{"bob@example.com"},
+	}
+	var r = &log4go.Record{Fields: []log4go.Field{
+		log4go.Any("headers", headers),
+		log4go.Any("user", redactUser{Name: "bob", Email: "bob@example.com"}),
+		log4go.Any("body", []byte(`{"card":"4111 1111 1111 1111"}`)),
+		log4go.Any("account", account),
+		log4go.Any("nested", map[string]interface{}{"request": map[string]interface{}{"token": 123, "ids": []int{1, 2}}}),
+		log4go.Any("ids", []int{1, 2}),
+		log4go.Any("plain", map[string]string{"name": "bob"}),
+	}}
+	rd.Redact(r)
+
+	var texts = make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		texts[i] = f.String()
+	}
+	var expected = []string{
+		`headers="map[Authorization:****** X-Request-Id:1]"`,
+		`user="bob <******>"`,
+		`body="{\"card\":\"******\"}"`,
+		`account="map[emails:[******] headers:map[Cookie:sid=1 X-Token:bearer ******] name:bob password:******]"`,
+		`nested="map[request:map[ids:[1 2] token:******]]"`,
+		`ids="[1 2]"`,
+		`plain=map[name:bob]`,
+	}
+	for i, s := range expected {
+		if texts[i] != s {
+			t.Errorf("字段 %d 应该为 %s, 实际为 %s", i, s, texts[i])
+		}
+	}
+
+	// 未修改的字段保留原来的值，不修改原来的值
+	if _, ok := r.Fields[5].Value.([]int); !ok {
+		t.Fatalf("未修改的字段不应该改变类型: %T", r.Fields[5].Value)
+	}
+	if headers["Authorization"] != "Bearer abc.def" || account.Password != "hunter2" || account.Headers["X-Token"] != "bearer abc.def" {
+		t.Fatal("不应该修改原来的字段值")
+	}
+}
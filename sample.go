@@ -0,0 +1,206 @@
+package log4go
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	kSampleBuckets = 4096
+)
+
+// Sampler 用于控制日志的数量，可以通过 Logger.SetSampler 作用于 Logger，
+// 也可以作为 Filter 传给 AddWriter 只作用于某个 Writer，Dropped 返回被丢弃的日志记录数量
+type Sampler interface {
+	Filter
+	DropCounter
+}
+
+type sampleKey struct {
+	level Level
+	hash  uint32
+}
+
+type countSampler struct {
+	dropped    uint64 // 保持 64 位对齐，供 atomic 使用
+	mu         sync.Mutex
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+	reset      time.Time
+	counts     map[sampleKey]uint64
+}
+
+// NewCountSampler 在每个 tick 时间段内，对级别和内容都相同的日志，前 first 条全部保留，
+// 之后每 thereafter 条保留一条，thereafter 为 0 时丢弃之后所有的日志。
+// 日志内容按哈希值分组，不同内容的日志可能会被当作相同的日志计数
+func NewCountSampler(tick time.Duration, first, thereafter int) Sampler {
+	if tick <= 0 {
+		tick = time.Second
+	}
+	if first < 0 {
+		first = 0
+	}
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	var s = &countSampler{}
+	s.tick = tick
+	s.first = uint64(first)
+	s.thereafter = uint64(thereafter)
+	return s
+}
+
+func (this *countSampler) Allow(r *Record) bool {
+	var h = fnv.New32a()
+	h.Write([]byte(r.Message))
+	var key = sampleKey{level: r.Level, hash: h.Sum32() % kSampleBuckets}
+
+	var now = time.Now()
+	this.mu.Lock()
+	if this.counts == nil || now.After(this.reset) {
+		this.counts = make(map[sampleKey]uint64)
+		this.reset = now.Add(this.tick)
+	}
+	var n = this.counts[key] + 1
+	this.counts[key] = n
+	this.mu.Unlock()
+
+	if n <= this.first || (this.thereafter > 0 && (n-this.first)%this.thereafter == 0) {
+		return true
+	}
+	atomic.AddUint64(&this.dropped, 1)
+	return false
+}
+
+func (this *countSampler) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *countSampler) String() string {
+	return fmt.Sprintf("sample(tick=%s,first=%d,thereafter=%d)", this.tick, this.first, this.thereafter)
+}
+
+type randomSampler struct {
+	dropped uint64
+	rates   map[Level]float64
+}
+
+// NewRandomSampler 按级别随机保留日志，rates 为各级别日志被保留的概率，取值范围为 [0, 1]，
+// 没有在 rates 中出现的级别全部保留
+func NewRandomSampler(rates map[Level]float64) Sampler {
+	var s = &randomSampler{}
+	s.rates = make(map[Level]float64, len(rates))
+	for level, rate := range rates {
+		s.rates[level] = rate
+	}
+	return s
+}
+
+func (this *randomSampler) Allow(r *Record) bool {
+	var rate, ok = this.rates[r.Level]
+	if !ok || rate >= 1 || (rate > 0 && rand.Float64() < rate) {
+		return true
+	}
+	atomic.AddUint64(&this.dropped, 1)
+	return false
+}
+
+func (this *randomSampler) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *randomSampler) String() string {
+	var levels = make([]Level, 0, len(this.rates))
+	for level := range this.rates {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i] < levels[j]
+	})
+
+	var rates = make([]string, 0, len(levels))
+	for _, level := range levels {
+		rates = append(rates, fmt.Sprintf("%s=%g", level, this.rates[level]))
+	}
+	return "random(" + strings.Join(rates, ",") + ")"
+}
+
+type rateSampler struct {
+	dropped uint64
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+}
+
+// NewRateSampler 使用令牌桶限制日志的速率，每秒最多保留 rate 条日志，允许最多 burst 条的突发
+func NewRateSampler(rate float64, burst int) Sampler {
+	if burst < 1 {
+		burst = 1
+	}
+	var s = &rateSampler{}
+	s.rate = rate
+	s.burst = float64(burst)
+	s.tokens = s.burst
+	s.last = time.Now()
+	return s
+}
+
+func (this *rateSampler) Allow(r *Record) bool {
+	var now = time.Now()
+	this.mu.Lock()
+	this.tokens += now.Sub(this.last).Seconds() * this.rate
+	if this.tokens > this.burst {
+		this.tokens = this.burst
+	}
+	this.last = now
+
+	var ok = this.tokens >= 1
+	if ok {
+		this.tokens--
+	}
+	this.mu.Unlock()
+
+	if !ok {
+		atomic.AddUint64(&this.dropped, 1)
+	}
+	return ok
+}
+
+func (this *rateSampler) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *rateSampler) String() string {
+	return fmt.Sprintf("rate(%g/s,burst=%g)", this.rate, this.burst)
+}
+
+type belowSampler struct {
+	level   Level
+	sampler Sampler
+}
+
+// SampleBelow 只对级别低于 level 的日志使用 s 采样，其它日志全部保留
+func SampleBelow(level Level, s Sampler) Sampler {
+	return &belowSampler{level: level, sampler: s}
+}
+
+func (this *belowSampler) Allow(r *Record) bool {
+	return r.Level >= this.level || this.sampler.Allow(r)
+}
+
+func (this *belowSampler) Dropped() uint64 {
+	return this.sampler.Dropped()
+}
+
+func (this *belowSampler) String() string {
+	return fmt.Sprintf("below(%s,%s)", this.level, filterString(this.sampler))
+}
@@ -0,0 +1,83 @@
+package log4go_test
+
+import (
+	"github.com/smartwalle/log4go"
+	"testing"
+	"time"
+)
+
+func TestCountSampler(t *testing.T) {
+	var s = log4go.NewCountSampler(time.Minute, 2, 3)
+	var l = log4go.New(log4go.WithSampler(s))
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	for i := 0; i < 10; i++ {
+		l.Infoln("hello")
+		l.Warnln("hello")
+	}
+	l.Infoln("world")
+
+	// 每种日志保留第 1、2、5、8 条
+	var records = w.Records()
+	if len(records) != 9 || s.Dropped() != 12 {
+		t.Fatalf("采样结果错误: %d %d", len(records), s.Dropped())
+	}
+	if records[0].File == "" || records[len(records)-1].Message != "world\n" {
+		t.Fatalf("日志记录信息错误: %v", records)
+	}
+}
+
+func TestRandomSampler(t *testing.T) {
+	var s = log4go.NewRandomSampler(map[log4go.Level]float64{log4go.LevelDebug: 0, log4go.LevelInfo: 1})
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w, s)
+
+	for i := 0; i < 10; i++ {
+		l.Debugln("hello")
+		l.Infoln("hello")
+		l.Warnln("hello")
+	}
+
+	if len(w.Records()) != 20 || s.Dropped() != 10 {
+		t.Fatalf("采样结果错误: %d %d", len(w.Records()), s.Dropped())
+	}
+	if infos := l.WriterInfos(); len(infos) != 1 || infos[0].Filtered != 10 || infos[0].Filter != "random(DEBUG=0,INFO=1)" {
+		t.Fatalf("Writer 信息错误: %+v", infos)
+	}
+}
+
+func TestRateSampler(t *testing.T) {
+	var s = log4go.NewRateSampler(0.001, 5)
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w, log4go.SampleBelow(log4go.LevelWarning, s))
+
+	for i := 0; i < 10; i++ {
+		l.Infoln("hello")
+		l.Warnln("hello")
+	}
+
+	if len(w.Records()) != 15 || s.Dropped() != 5 {
+		t.Fatalf("采样结果错误: %d %d", len(w.Records()), s.Dropped())
+	}
+}
+
+func TestLogger_SamplerInherit(t *testing.T) {
+	var s = log4go.NewRateSampler(0.001, 1)
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+	l.SetSampler(s)
+
+	var c = l.GetLogger("child")
+	if c.Sampler() != s {
+		t.Fatal("子 Logger 应该沿用父 Logger 的 Sampler")
+	}
+	c.Infoln("hello")
+	c.Infoln("hello")
+	if len(w.Records()) != 1 || s.Dropped() != 1 {
+		t.Fatalf("采样结果错误: %d %d", len(w.Records()), s.Dropped())
+	}
+}
@@ -0,0 +1,205 @@
+//go:build go1.21
+// +build go1.21
+
+package log4go
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// slogLevel 将 slog 的级别转换为 log4go 的级别，低于 slog.LevelInfo 的均为 LevelDebug
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarning
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	}
+	return LevelDebug
+}
+
+// toSlogLevel 将 log4go 的级别转换为 slog 的级别，自定义级别按照其下方最接近的内置级别转换
+func toSlogLevel(level Level) slog.Level {
+	switch {
+	case level >= LevelFatal:
+		return slog.LevelError + 8
+	case level >= LevelPanic:
+		return slog.LevelError + 4
+	case level >= LevelError:
+		return slog.LevelError
+	case level >= LevelWarning:
+		return slog.LevelWarn
+	case level >= LevelInfo:
+		return slog.LevelInfo
+	case level >= LevelDebug:
+		return slog.LevelDebug
+	}
+	return slog.LevelDebug - 4
+}
+
+type slogHandler struct {
+	logger Logger
+	fields []Field
+	group  string
+}
+
+// NewSlogHandler 返回使用 l 输出日志的 slog.Handler，l 为 nil 时使用 SharedLogger，
+// slog 的 group 和 attr 将转换为字段，group 中的 key 以 "." 连接，如 request.id
+func NewSlogHandler(l Logger) slog.Handler {
+	if l == nil {
+		l = sharedLogger
+	}
+	return &slogHandler{logger: l}
+}
+
+func (this *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slogLevel(level) >= this.logger.Level()
+}
+
+func (this *slogHandler) Handle(ctx context.Context, sr slog.Record) error {
+	var r = &Record{
+		Time:    sr.Time,
+		Level:   slogLevel(sr.Level),
+		Message: sr.Message + "\n",
+		Context: ctx,
+	}
+
+	if sr.PC != 0 {
+		var frame, _ = runtime.CallersFrames([]uintptr{sr.PC}).Next()
+		r.File = frame.File
+		r.Line = frame.Line
+	} else {
+		r.File = "???"
+		r.Line = -1
+	}
+
+	r.Fields = make([]Field, 0, len(this.fields)+sr.NumAttrs())
+	r.Fields = append(r.Fields, this.fields...)
+	sr.Attrs(func(attr slog.Attr) bool {
+		r.Fields = appendSlogAttr(r.Fields, this.group, attr)
+		return true
+	})
+
+	this.logger.WriteRecord(r)
+	return nil
+}
+
+func (this *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return this
+	}
+	var h = *this
+	h.fields = make([]Field, 0, len(this.fields)+len(attrs))
+	h.fields = append(h.fields, this.fields...)
+	for _, attr := range attrs {
+		h.fields = appendSlogAttr(h.fields, this.group, attr)
+	}
+	return &h
+}
+
+func (this *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return this
+	}
+	var h = *this
+	h.group = this.group + name + "."
+	return &h
+}
+
+func appendSlogAttr(fields []Field, group string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		var attrs = attr.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		// key 为空的 group 直接展开到当前层级
+		if attr.Key != "" {
+			group = group + attr.Key + "."
+		}
+		for _, a := range attrs {
+			fields = appendSlogAttr(fields, group, a)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: group + attr.Key, Value: attr.Value.Any()})
+}
+
+// SlogWriter 将日志记录转发给 slog.Handler，用于和使用 log/slog 的代码共用同一个输出
+type SlogWriter struct {
+	level   int32
+	handler slog.Handler
+}
+
+func NewSlogWriter(level Level, h slog.Handler) *SlogWriter {
+	var sw = &SlogWriter{}
+	sw.level = int32(level)
+	sw.handler = h
+	return sw
+}
+
+func (this *SlogWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var r = slog.NewRecord(time.Now(), slog.LevelInfo, strings.TrimRight(string(p), "\n"), 0)
+	return len(p), this.handler.Handle(context.Background(), r)
+}
+
+func (this *SlogWriter) Close() error {
+	return nil
+}
+
+func (this *SlogWriter) Level() Level {
+	return Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *SlogWriter) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *SlogWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *SlogWriter) WriteRecord(r *Record) {
+	var ctx = r.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var level = toSlogLevel(r.Level)
+	if !this.handler.Enabled(ctx, level) {
+		return
+	}
+
+	var sr = slog.NewRecord(r.Time, level, strings.TrimRight(r.Message, "\n"), 0)
+	sr.AddAttrs(slog.Any(slog.SourceKey, &slog.Source{File: r.File, Line: r.Line}))
+	if r.Logger != "" {
+		sr.AddAttrs(slog.String("logger", r.Logger))
+	}
+	if r.Service != "" {
+		sr.AddAttrs(slog.String("service", r.Service))
+	}
+	if r.Instance != "" {
+		sr.AddAttrs(slog.String("instance", r.Instance))
+	}
+	if r.Prefix != "" {
+		sr.AddAttrs(slog.String("prefix", r.Prefix))
+	}
+	for _, f := range r.Fields {
+		sr.AddAttrs(slog.Any(f.Key, f.Value))
+	}
+	this.handler.Handle(ctx, sr)
+}
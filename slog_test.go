@@ -0,0 +1,62 @@
+//go:build go1.21
+// +build go1.21
+
+package log4go_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"log/slog"
+	"path/filepath"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var l = log4go.New()
+	l.SetLevel(log4go.LevelInfo)
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	var sl = slog.New(log4go.NewSlogHandler(l)).With("service", "order").WithGroup("req")
+	sl.Debug("debug")
+	sl.Warn("hello", "id", 1, slog.Group("user", "name", "test"))
+
+	var records = w.Records()
+	if len(records) != 1 {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+
+	var r = records[0]
+	if r.Level != log4go.LevelWarning || r.Message != "hello\n" || filepath.Base(r.File) != "slog_test.go" {
+		t.Fatalf("日志记录信息错误: %v %q %s", r.Level, r.Message, r.File)
+	}
+
+	var fields []string
+	for _, f := range r.Fields {
+		fields = append(fields, f.String())
+	}
+	if len(fields) != 3 || fields[0] != "service=order" || fields[1] != "req.id=1" || fields[2] != "req.user.name=test" {
+		t.Fatalf("日志记录中的字段错误: %v", fields)
+	}
+}
+
+func TestSlogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var l = log4go.New(log4go.WithService("order"))
+	l.AddWriter("slog", log4go.NewSlogWriter(log4go.LevelTrace, slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	l.Debugln("debug")
+	l.Warnln("hello")
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	if obj["level"] != "WARN" || obj["msg"] != "hello" || obj["service"] != "order" {
+		t.Fatalf("slog 收到的日志记录错误: %s", buf.String())
+	}
+	if source, _ := obj["source"].(map[string]interface{}); source == nil || filepath.Base(source["file"].(string)) != "slog_test.go" {
+		t.Fatalf("slog 收到的日志记录缺少 source: %s", buf.String())
+	}
+}
@@ -0,0 +1,85 @@
+package log4go
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+type StackMode int
+
+const (
+	StackAll     StackMode = iota // 所有 goroutine 的调用栈，以文本形式追加在日志消息之后
+	StackCurrent                  // 当前 goroutine 的调用栈，保存在 Record.Stack 中
+)
+
+const (
+	kStackMaxBytes  = 1 << 20
+	kStackMaxFrames = 64
+)
+
+// StackFrame 为调用栈中的一帧
+type StackFrame struct {
+	Function string `json:"func"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+var kPackagePrefix = reflect.TypeOf(logger{}).PkgPath() + "."
+
+// allStack 返回所有 goroutine 的调用栈，缓冲区不足时成倍扩大，最大不超过 limit 字节
+func allStack(limit int) string {
+	if limit <= 0 {
+		limit = kStackMaxBytes
+	}
+
+	var size = 4096
+	for {
+		if size > limit {
+			size = limit
+		}
+		var buf = make([]byte, size)
+		var n = runtime.Stack(buf, true)
+		if n < size || size >= limit {
+			return string(buf[:n])
+		}
+		size *= 2
+	}
+}
+
+// currentStack 返回当前 goroutine 从 skip 层开始的调用栈，skip 为 0 表示调用 currentStack 的函数，
+// 位于栈顶的 log4go 及辅助函数会被跳过，最多返回 limit 帧
+func currentStack(skip, limit int) []StackFrame {
+	if limit <= 0 {
+		limit = kStackMaxFrames
+	}
+
+	var pcs []uintptr
+	var size = 32
+	for {
+		if size > limit {
+			size = limit
+		}
+		pcs = make([]uintptr, size)
+		var n = runtime.Callers(skip+2, pcs)
+		if n < size || size >= limit {
+			pcs = pcs[:n]
+			break
+		}
+		size *= 2
+	}
+
+	var stack = make([]StackFrame, 0, len(pcs))
+	var frames = runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		var internal = strings.HasPrefix(frame.Function, kPackagePrefix) || isHelper(frame.Function)
+		if frame.Function != "" && (len(stack) > 0 || !internal) {
+			stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
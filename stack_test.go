@@ -0,0 +1,62 @@
+package log4go_test
+
+import (
+	"encoding/json"
+	"github.com/smartwalle/log4go"
+	"strings"
+	"testing"
+)
+
+func TestLogger_StackCurrent(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+	l.EnableStack()
+	l.SetStackLevel(log4go.LevelInfo)
+	l.SetStackMode(log4go.StackCurrent)
+
+	l.Debugln("debug")
+	logWithHelper(l.With(), "helper")
+	l.Warnln("warn")
+
+	var records = w.Records()
+	if len(records) != 3 || len(records[0].Stack) != 0 {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+
+	for _, r := range records[1:] {
+		if len(r.Stack) == 0 || !strings.HasSuffix(r.Stack[0].Function, "log4go_test.TestLogger_StackCurrent") {
+			t.Fatalf("调用栈错误: %v", r.Stack)
+		}
+		if strings.Contains(r.Message, "goroutine") {
+			t.Fatalf("StackCurrent 模式不应该在消息中输出调用栈: %s", r.Message)
+		}
+	}
+
+	var stack = records[2].Stack
+	var obj struct {
+		Stack []log4go.StackFrame `json:"stack"`
+	}
+	if err := json.Unmarshal(log4go.NewJSONFormatter().Format(&records[2]), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if len(obj.Stack) != len(stack) || obj.Stack[0] != stack[0] {
+		t.Fatalf("JSON 格式中的调用栈错误: %v", obj.Stack)
+	}
+}
+
+func TestLogger_StackAll(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+	l.EnableStack()
+	l.SetStackLevel(log4go.LevelTrace)
+	l.SetStackLimit(100)
+
+	l.Infoln("info")
+
+	var records = w.Records()
+	if len(records) != 1 || !strings.HasPrefix(records[0].Message, "info\ngoroutine ") || len(records[0].Message) > len("info\n")+100+1 {
+		t.Fatalf("调用栈错误: %q", records[0].Message)
+	}
+}
@@ -1,92 +1,148 @@
 package log4go
 
 import (
-	"fmt"
 	"github.com/mattn/go-isatty"
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
-//30 black		黑色
-//31 red		红色
-//32 green		绿色
-//33 yellow		黄色
-//34 blue		蓝色
-//35 magenta    洋红
-//36 cyan		天蓝色
-//37 white		白色
-
-func black(c string) string {
-	return fmt.Sprintf("\033[1;30m%s\033[0m", c)
+type StdWriterOption interface {
+	Apply(*StdWriter)
 }
 
-func red(c string) string {
-	return fmt.Sprintf("\033[1;31m%s\033[0m", c)
+type swOptionFunc func(*StdWriter)
+
+func (f swOptionFunc) Apply(w *StdWriter) {
+	f(w)
 }
 
-func green(c string) string {
-	return fmt.Sprintf("\033[1;32m%s\033[0m", c)
+// WithOutput 设置输出目标，默认为 os.Stdout，ColorAuto 时只有终端才会着色
+func WithOutput(out io.Writer) StdWriterOption {
+	return swOptionFunc(func(w *StdWriter) {
+		if out != nil {
+			w.out = out
+		}
+	})
 }
 
-func yellow(c string) string {
-	return fmt.Sprintf("\033[1;33m%s\033[0m", c)
+func WithColor(mode ColorMode) StdWriterOption {
+	return swOptionFunc(func(w *StdWriter) {
+		w.colorMode = mode
+	})
 }
 
-func blue(c string) string {
-	return fmt.Sprintf("\033[1;34m%s\033[0m", c)
+// WithPalette 设置各日志级别的颜色，未设置的级别沿用默认颜色
+func WithPalette(p *Palette) StdWriterOption {
+	return swOptionFunc(func(w *StdWriter) {
+		if p == nil {
+			return
+		}
+		for level, c := range p.Levels {
+			w.palette.Levels[level] = c
+		}
+		w.palette.File = p.File
+		w.palette.Message = p.Message
+	})
 }
 
-func magenta(c string) string {
-	return fmt.Sprintf("\033[1;35m%s\033[0m", c)
+func WithLevelColor(level Level, c Color) StdWriterOption {
+	return swOptionFunc(func(w *StdWriter) {
+		w.palette.Levels[level] = c
+	})
 }
 
-func skyBlue(c string) string {
-	return fmt.Sprintf("\033[1;36m%s\033[0m", c)
+func WithFileColor(c Color) StdWriterOption {
+	return swOptionFunc(func(w *StdWriter) {
+		w.palette.File = c
+	})
 }
 
-func white(c string) string {
-	return fmt.Sprintf("\033[1;37m%s\033[0m", c)
+func WithMessageColor(c Color) StdWriterOption {
+	return swOptionFunc(func(w *StdWriter) {
+		w.palette.Message = c
+	})
 }
 
-var (
-	levelColors = []string{
-		white(LevelNames[0]),
-		green(LevelNames[1]),
-		blue(LevelNames[2]),
-		yellow(LevelNames[3]),
-		magenta(LevelNames[4]),
-		red(LevelNames[5]),
-		red(LevelNames[6]),
-	}
-)
+// WithLevelStyle 设置默认 TextFormatter 的级别样式
+func WithLevelStyle(style LevelStyle) StdWriterOption {
+	return swOptionFunc(func(w *StdWriter) {
+		w.levelStyle = style
+	})
+}
 
 type StdWriter struct {
-	level       Level
+	level       int32
 	out         io.Writer
 	mutex       sync.Mutex
+	colorMode   ColorMode
+	palette     *Palette
 	enableColor bool
+	levelStyle  LevelStyle
+	formatter   Formatter
+	observed
 }
 
-func NewStdWriter(level Level) *StdWriter {
+func NewStdWriter(level Level, opts ...StdWriterOption) *StdWriter {
 	var sw = &StdWriter{}
-	sw.level = level
+	sw.level = int32(level)
 	sw.out = os.Stdout
-	sw.enableColor = true
-	if w, ok := sw.out.(*os.File); !ok || (os.Getenv("TERM") == "dumb" || (!isatty.IsTerminal(w.Fd()) && !isatty.IsCygwinTerminal(w.Fd()))) {
-		sw.enableColor = false
+	sw.colorMode = ColorAuto
+	sw.palette = DefaultPalette()
+	sw.setObservedName("std")
+	for _, opt := range opts {
+		opt.Apply(sw)
 	}
+	sw.enableColor = sw.shouldColor()
+
+	var tf = NewTextFormatter()
+	tf.LevelStyle = sw.levelStyle
+	if sw.enableColor {
+		tf.Palette = sw.palette
+	}
+	sw.formatter = tf
 	return sw
 }
 
+func (this *StdWriter) shouldColor() bool {
+	switch this.colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if enable, ok := colorFromEnv(); ok {
+		return enable
+	}
+
+	if w, ok := this.out.(*os.File); !ok || (os.Getenv("TERM") == "dumb" || (!isatty.IsTerminal(w.Fd()) && !isatty.IsCygwinTerminal(w.Fd()))) {
+		return false
+	}
+	return true
+}
+
+// ColorEnabled 返回是否输出颜色，由 WithColor 及环境变量决定
+func (this *StdWriter) ColorEnabled() bool {
+	return this.enableColor
+}
+
+func (this *StdWriter) Palette() *Palette {
+	return this.palette.clone()
+}
+
 func (this *StdWriter) Write(p []byte) (n int, err error) {
 	if len(p) == 0 {
 		return 0, nil
 	}
 
 	this.mutex.Lock()
-	defer this.mutex.Unlock()
-	return this.out.Write(p)
+	n, err = this.out.Write(p)
+	this.mutex.Unlock()
+
+	this.observeWrite(n, err)
+	return n, err
 }
 
 func (this *StdWriter) Close() error {
@@ -94,13 +150,33 @@ func (this *StdWriter) Close() error {
 }
 
 func (this *StdWriter) Level() Level {
-	return this.level
+	return Level(atomic.LoadInt32(&this.level))
 }
 
-func (this *StdWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
-	var levelName = LevelNames[level]
-	if this.enableColor {
-		levelName = levelColors[level]
+func (this *StdWriter) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+// SetFormatter 设置输出格式，自定义的 Formatter 需要自行处理颜色
+func (this *StdWriter) SetFormatter(f Formatter) {
+	if f == nil {
+		return
 	}
-	fmt.Fprintf(this, "%s%s%s%s %s %s:%d %s", service, instance, prefix, logTime, levelName, file, line, msg)
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.formatter = f
+}
+
+func (this *StdWriter) Formatter() Formatter {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.formatter
+}
+
+func (this *StdWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *StdWriter) WriteRecord(r *Record) {
+	this.Write(this.Formatter().Format(r))
 }
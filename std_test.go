@@ -0,0 +1,141 @@
+package log4go_test
+
+import (
+	"bytes"
+	"github.com/smartwalle/log4go"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// setEnv 设置环境变量，值为 nil 时删除，返回恢复原值的函数
+func setEnv(env map[string]*string) func() {
+	var restore = make(map[string]*string, len(env))
+	for key, value := range env {
+		if old, ok := os.LookupEnv(key); ok {
+			restore[key] = &old
+		} else {
+			restore[key] = nil
+		}
+		if value == nil {
+			os.Unsetenv(key)
+		} else {
+			os.Setenv(key, *value)
+		}
+	}
+	return func() {
+		for key, value := range restore {
+			if value == nil {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, *value)
+			}
+		}
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestStdWriter_ColorMode(t *testing.T) {
+	file, err := ioutil.TempFile("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	var tests = []struct {
+		name     string
+		mode     log4go.ColorMode
+		noColor  *string
+		force    *string
+		file     bool
+		expected bool
+	}{
+		{name: "auto buffer", mode: log4go.ColorAuto, expected: false},
+		{name: "auto file", mode: log4go.ColorAuto, file: true, expected: false},
+		{name: "auto force", mode: log4go.ColorAuto, force: strPtr("1"), expected: true},
+		{name: "auto force file", mode: log4go.ColorAuto, force: strPtr("1"), file: true, expected: true},
+		{name: "auto force 0", mode: log4go.ColorAuto, force: strPtr("0"), expected: false},
+		{name: "auto force false", mode: log4go.ColorAuto, force: strPtr("false"), expected: false},
+		{name: "auto no color", mode: log4go.ColorAuto, noColor: strPtr("1"), expected: false},
+		{name: "auto empty no color", mode: log4go.ColorAuto, noColor: strPtr(""), expected: false},
+		{name: "auto empty force", mode: log4go.ColorAuto, force: strPtr(""), expected: false},
+		{name: "auto empty force file", mode: log4go.ColorAuto, force: strPtr(""), file: true, expected: false},
+		{name: "auto empty force and no color", mode: log4go.ColorAuto, noColor: strPtr("1"), force: strPtr(""), expected: false},
+		{name: "auto force and no color", mode: log4go.ColorAuto, noColor: strPtr("1"), force: strPtr("1"), expected: true},
+		{name: "always", mode: log4go.ColorAlways, expected: true},
+		{name: "always no color", mode: log4go.ColorAlways, noColor: strPtr("1"), expected: true},
+		{name: "never", mode: log4go.ColorNever, expected: false},
+		{name: "never force", mode: log4go.ColorNever, force: strPtr("1"), expected: false},
+	}
+
+	for _, test := range tests {
+		var restore = setEnv(map[string]*string{"NO_COLOR": test.noColor, "FORCE_COLOR": test.force})
+
+		var buf = &bytes.Buffer{}
+		var opts = []log4go.StdWriterOption{log4go.WithColor(test.mode), log4go.WithOutput(buf)}
+		if test.file {
+			opts[1] = log4go.WithOutput(file)
+		}
+		var sw = log4go.NewStdWriter(log4go.LevelTrace, opts...)
+		restore()
+
+		if sw.ColorEnabled() != test.expected {
+			t.Fatalf("%s: 期望着色 %v, 实际 %v", test.name, test.expected, sw.ColorEnabled())
+		}
+		if test.file {
+			continue
+		}
+		sw.WriteRecord(&log4go.Record{Level: log4go.LevelInfo, File: "main.go", Line: 1, Message: "hello\n"})
+		if strings.Contains(buf.String(), "\033[") != test.expected {
+			t.Fatalf("%s: 输出内容错误 %q", test.name, buf.String())
+		}
+	}
+}
+
+func TestStdWriter_Palette(t *testing.T) {
+	var buf = &bytes.Buffer{}
+	var sw = log4go.NewStdWriter(log4go.LevelTrace, log4go.WithOutput(buf), log4go.WithColor(log4go.ColorAlways),
+		log4go.WithPalette(&log4go.Palette{Levels: map[log4go.Level]log4go.Color{log4go.LevelError: log4go.ColorRGB(255, 0, 128)}}),
+		log4go.WithLevelColor(log4go.LevelInfo, log4go.Color256(208)),
+		log4go.WithFileColor(log4go.ColorRGB(1, 2, 3)),
+		log4go.WithMessageColor(log4go.ColorGreen),
+	)
+
+	var tests = []struct {
+		level    log4go.Level
+		expected []string
+	}{
+		{log4go.LevelInfo, []string{"\033[38;5;208m[I]\033[0m", "\033[38;2;1;2;3mmain.go:10\033[0m", "\033[1;32mhello\033[0m\n"}},
+		{log4go.LevelError, []string{"\033[38;2;255;0;128m[E]\033[0m"}},
+		// 未设置的级别使用默认颜色
+		{log4go.LevelWarning, []string{"\033[1;33m[W]\033[0m"}},
+	}
+
+	for _, test := range tests {
+		buf.Reset()
+		sw.WriteRecord(&log4go.Record{Level: test.level, File: "main.go", Line: 10, Message: "hello\n"})
+		for _, s := range test.expected {
+			if !strings.Contains(buf.String(), s) {
+				t.Fatalf("%s: 输出内容 %q 中没有 %q", test.level, buf.String(), s)
+			}
+		}
+	}
+
+	if p := sw.Palette(); p.Level(log4go.LevelInfo) != log4go.Color256(208) || p.File != log4go.ColorRGB(1, 2, 3) || p.Message != log4go.ColorGreen {
+		t.Fatalf("Palette 错误: %+v", p)
+	}
+}
+
+func TestStdWriter_NoColor(t *testing.T) {
+	var buf = &bytes.Buffer{}
+	var sw = log4go.NewStdWriter(log4go.LevelTrace, log4go.WithOutput(buf), log4go.WithColor(log4go.ColorNever), log4go.WithMessageColor(log4go.ColorGreen))
+	sw.WriteRecord(&log4go.Record{Level: log4go.LevelInfo, File: "main.go", Line: 10, Message: "hello\n"})
+	if !strings.HasSuffix(buf.String(), " [I] main.go:10 hello\n") {
+		t.Fatalf("输出内容错误: %q", buf.String())
+	}
+}
@@ -0,0 +1,76 @@
+package log4go
+
+import (
+	"io"
+	"log"
+	"strings"
+)
+
+// stdLogWriter 将标准库 log 包的输出转交给 Logger
+type stdLogWriter struct {
+	logger Logger
+	level  Level
+}
+
+// NewStdLogWriter 返回将写入内容以 level 级别输出到 l 的 io.Writer，
+// 日志中的文件及行号为调用 log 包的位置
+func NewStdLogWriter(l Logger, level Level) io.Writer {
+	if l == nil {
+		l = sharedLogger
+	}
+	return &stdLogWriter{logger: l, level: level}
+}
+
+func (this *stdLogWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	var msg = string(p)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+
+	var file, line, function = stdLogCaller()
+	this.logger.WriteRecord(&Record{Level: this.level, File: file, Line: line, Function: function, Message: msg})
+	return len(p), nil
+}
+
+// stdLogCaller 跳过 log 包及 stdLogWriter 的调用栈，返回调用 log 包的位置
+func stdLogCaller() (string, int, string) {
+	var frame, ok = callerFrame(2, func(function string) bool {
+		return strings.HasPrefix(function, "log.")
+	})
+	if !ok {
+		return "???", -1, ""
+	}
+	return frame.File, frame.Line, shortFunction(frame.Function)
+}
+
+// NewStdLogger 返回将日志以 level 级别输出到 l 的 *log.Logger，可用于 http.Server.ErrorLog 等
+func NewStdLogger(l Logger, level Level) *log.Logger {
+	return log.New(NewStdLogWriter(l, level), "", 0)
+}
+
+// StdLogger 返回将日志以 level 级别输出到 SharedLogger 的 *log.Logger
+func StdLogger(level Level) *log.Logger {
+	return NewStdLogger(sharedLogger, level)
+}
+
+// RedirectStdLog 将标准库 log 包默认 Logger 的输出以 level 级别重定向到 SharedLogger，
+// 返回的函数用于恢复 log 包原来的设置
+func RedirectStdLog(level Level) func() {
+	var flags = log.Flags()
+	var prefix = log.Prefix()
+	var out = log.Writer()
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(NewStdLogWriter(sharedLogger, level))
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(out)
+	}
+}
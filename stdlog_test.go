@@ -0,0 +1,37 @@
+package log4go_test
+
+import (
+	"github.com/smartwalle/log4go"
+	"log"
+	"path/filepath"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	var l = log4go.New()
+	var w = &memoryWriter{}
+	l.AddWriter("memory", w)
+
+	var sl = log4go.NewStdLogger(l, log4go.LevelError)
+	sl.Printf("hello %s", "world")
+
+	var records = w.Records()
+	if len(records) != 1 || records[0].Level != log4go.LevelError || records[0].Message != "hello world\n" || filepath.Base(records[0].File) != "stdlog_test.go" {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	var w = &memoryWriter{}
+	log4go.AddWriter("memory", w)
+	defer log4go.RemoveWriter("memory")
+
+	var restore = log4go.RedirectStdLog(log4go.LevelWarning)
+	log.Println("from std log")
+	restore()
+
+	var records = w.Records()
+	if len(records) != 1 || records[0].Level != log4go.LevelWarning || records[0].Message != "from std log\n" || filepath.Base(records[0].File) != "stdlog_test.go" {
+		t.Fatalf("writer 收到的日志记录错误: %v", records)
+	}
+}
@@ -0,0 +1,412 @@
+package log4go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type SyslogFacility int
+
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+)
+
+const (
+	FacilityLocal0 SyslogFacility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+var facilityNames = map[string]SyslogFacility{
+	"kern":     FacilityKern,
+	"user":     FacilityUser,
+	"mail":     FacilityMail,
+	"daemon":   FacilityDaemon,
+	"auth":     FacilityAuth,
+	"syslog":   FacilitySyslog,
+	"lpr":      FacilityLpr,
+	"news":     FacilityNews,
+	"uucp":     FacilityUucp,
+	"cron":     FacilityCron,
+	"authpriv": FacilityAuthPriv,
+	"ftp":      FacilityFTP,
+	"local0":   FacilityLocal0,
+	"local1":   FacilityLocal1,
+	"local2":   FacilityLocal2,
+	"local3":   FacilityLocal3,
+	"local4":   FacilityLocal4,
+	"local5":   FacilityLocal5,
+	"local6":   FacilityLocal6,
+	"local7":   FacilityLocal7,
+}
+
+// ParseSyslogFacility 解析 facility 的名称，如 user、daemon、local0
+func ParseSyslogFacility(s string) (SyslogFacility, error) {
+	if f, ok := facilityNames[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return f, nil
+	}
+	return FacilityUser, fmt.Errorf("未知的 syslog facility %q", s)
+}
+
+type SyslogFormat int
+
+const (
+	SyslogRFC5424 SyslogFormat = iota
+	SyslogRFC3164
+)
+
+// ParseSyslogFormat 解析 syslog 的消息格式，可以为 rfc5424 或者 rfc3164
+func ParseSyslogFormat(s string) (SyslogFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "rfc5424", "5424":
+		return SyslogRFC5424, nil
+	case "rfc3164", "3164":
+		return SyslogRFC3164, nil
+	}
+	return SyslogRFC5424, fmt.Errorf("未知的 syslog 格式 %q", s)
+}
+
+const (
+	kSyslogTimeLayout   = "2006-01-02T15:04:05.000000Z07:00"
+	kSyslogDialTimeout  = 5 * time.Second
+	kSyslogMinBackoff   = time.Second
+	kSyslogMaxBackoff   = 30 * time.Second
+	kSyslogStructuredId = "fields@32473"
+)
+
+// PARAM-VALUE 中的 "、\ 和 ] 需要转义
+var kSyslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// 本机 syslog 服务常用的 unix socket 路径
+var kSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+type SyslogWriterOption interface {
+	Apply(*SyslogWriter)
+}
+
+type slOptionFunc func(*SyslogWriter)
+
+func (f slOptionFunc) Apply(w *SyslogWriter) {
+	f(w)
+}
+
+func WithFacility(f SyslogFacility) SyslogWriterOption {
+	return slOptionFunc(func(w *SyslogWriter) {
+		w.facility = f
+	})
+}
+
+func WithSyslogFormat(format SyslogFormat) SyslogWriterOption {
+	return slOptionFunc(func(w *SyslogWriter) {
+		w.format = format
+	})
+}
+
+// WithAppName 设置 APP-NAME（RFC 3164 中为 TAG），默认为当前程序的名称
+func WithAppName(name string) SyslogWriterOption {
+	return slOptionFunc(func(w *SyslogWriter) {
+		if name != "" {
+			w.appName = name
+		}
+	})
+}
+
+// WithHostname 设置 HOSTNAME，默认为当前主机的名称
+func WithHostname(hostname string) SyslogWriterOption {
+	return slOptionFunc(func(w *SyslogWriter) {
+		if hostname != "" {
+			w.hostname = hostname
+		}
+	})
+}
+
+// WithStructuredDataId 设置 RFC 5424 中用于保存字段的 SD-ID，默认为 fields@32473
+func WithStructuredDataId(id string) SyslogWriterOption {
+	return slOptionFunc(func(w *SyslogWriter) {
+		if id != "" {
+			w.sdId = id
+		}
+	})
+}
+
+// WithSyslogBackoff 设置连接失败之后的等待时间，从 min 开始每次失败后加倍，最大为 max，
+// 等待期间写入的日志直接返回错误，不再尝试连接
+func WithSyslogBackoff(min, max time.Duration) SyslogWriterOption {
+	return slOptionFunc(func(w *SyslogWriter) {
+		if min > 0 {
+			w.conn.backoff.min = min
+		}
+		if max >= w.conn.backoff.min {
+			w.conn.backoff.max = max
+		}
+	})
+}
+
+// SyslogWriter 将日志发送到 syslog 服务，RFC 5424 格式下字段以 STRUCTURED-DATA 的形式发送，
+// RFC 3164 格式下字段以 key=value 的形式追加在消息之后
+type SyslogWriter struct {
+	level     int32
+	mu        sync.Mutex
+	network   string
+	addr      string
+	facility  SyslogFacility
+	format    SyslogFormat
+	hostname  string
+	appName   string
+	sdId      string
+	pid       int
+	formatter Formatter
+	conn      retryConn
+	stream    bool
+	observed
+}
+
+// NewSyslogWriter 创建 SyslogWriter，network 可以为 udp、tcp 或者 unix、unixgram，
+// network 为空时连接本机的 syslog 服务（如 /dev/log）。连接在第一次写入时建立，写入失败时会重新连接，
+// 连接失败之后在等待时间内不再尝试连接，参考 WithSyslogBackoff。
+// TCP 和 unix 连接使用 RFC 6587 中的 octet-counting 分帧
+func NewSyslogWriter(level Level, network, addr string, opts ...SyslogWriterOption) *SyslogWriter {
+	var sw = &SyslogWriter{}
+	sw.level = int32(level)
+	sw.network = network
+	sw.addr = addr
+	sw.facility = FacilityUser
+	sw.format = SyslogRFC5424
+	sw.hostname, _ = os.Hostname()
+	sw.appName = filepath.Base(os.Args[0])
+	sw.sdId = kSyslogStructuredId
+	sw.pid = os.Getpid()
+	sw.conn.backoff.min = kSyslogMinBackoff
+	sw.conn.backoff.max = kSyslogMaxBackoff
+	sw.setObservedName("syslog")
+	for _, opt := range opts {
+		opt.Apply(sw)
+	}
+	return sw
+}
+
+// Write 以 Info 级别将 p 作为消息发送
+func (this *SyslogWriter) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var r = &Record{Time: time.Now(), Level: LevelInfo}
+	if err = this.send(this.encode(r, bytes.TrimRight(p, "\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (this *SyslogWriter) Close() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.conn.close()
+}
+
+func (this *SyslogWriter) Level() Level {
+	return Level(atomic.LoadInt32(&this.level))
+}
+
+func (this *SyslogWriter) SetLevel(level Level) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+// SetFormatter 设置消息部分的格式，默认只发送日志消息及调用栈，时间、级别等信息由 syslog 协议本身表示
+func (this *SyslogWriter) SetFormatter(f Formatter) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.formatter = f
+}
+
+func (this *SyslogWriter) Formatter() Formatter {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.formatter
+}
+
+func (this *SyslogWriter) WriteMessage(service, instance, prefix, logTime string, level Level, file string, line int, msg string) {
+	this.WriteRecord(NewMessageRecord(service, instance, prefix, logTime, level, file, line, msg))
+}
+
+func (this *SyslogWriter) WriteRecord(r *Record) {
+	var body []byte
+	if f := this.Formatter(); f != nil {
+		body = bytes.TrimRight(f.Format(r), "\n")
+	} else {
+		var buf bytes.Buffer
+		buf.WriteString(strings.TrimRight(r.Message, "\n"))
+		if this.format == SyslogRFC3164 {
+			writeTextFields(&buf, r.Fields)
+		}
+		if len(r.Stack) > 0 {
+			buf.WriteByte('\n')
+			writeTextStack(&buf, r.Stack)
+		}
+		body = bytes.TrimRight(buf.Bytes(), "\n")
+	}
+	this.send(this.encode(r, body))
+}
+
+func (this *SyslogWriter) encode(r *Record, body []byte) []byte {
+	var priority = int(this.facility)*8 + r.Level.SyslogSeverity()
+
+	var buf bytes.Buffer
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(priority))
+	buf.WriteByte('>')
+
+	if this.format == SyslogRFC3164 {
+		buf.WriteString(r.Time.Format(time.Stamp))
+		buf.WriteByte(' ')
+		buf.WriteString(syslogHeader(this.hostname, 255))
+		buf.WriteByte(' ')
+		buf.WriteString(syslogHeader(this.appName, 32))
+		buf.WriteByte('[')
+		buf.WriteString(strconv.Itoa(this.pid))
+		buf.WriteString("]: ")
+		buf.Write(body)
+		return buf.Bytes()
+	}
+
+	buf.WriteString("1 ")
+	buf.WriteString(r.Time.Format(kSyslogTimeLayout))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeader(this.hostname, 255))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeader(this.appName, 48))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(this.pid))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeader(r.Logger, 32))
+	buf.WriteByte(' ')
+	this.writeStructuredData(&buf, r.Fields)
+	if len(body) > 0 {
+		buf.WriteByte(' ')
+		buf.Write(body)
+	}
+	return buf.Bytes()
+}
+
+// writeStructuredData 以 [fields@32473 key="value" ...] 的形式输出字段，没有字段时输出 -
+func (this *SyslogWriter) writeStructuredData(buf *bytes.Buffer, fields []Field) {
+	if len(fields) == 0 {
+		buf.WriteByte('-')
+		return
+	}
+	buf.WriteByte('[')
+	buf.WriteString(this.sdId)
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(syslogParamName(f.Key))
+		buf.WriteString(`="`)
+		buf.WriteString(kSyslogParamEscaper.Replace(fieldString(f.Value)))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte(']')
+}
+
+// syslogHeader 将头部字段限制为不包含空格的可打印 ASCII 字符，为空时使用 -
+func syslogHeader(s string, max int) string {
+	var b = make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < max; i++ {
+		if s[i] > ' ' && s[i] < 0x7f {
+			b = append(b, s[i])
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// syslogParamName PARAM-NAME 不能包含 =、空格、]、"，最长 32 个字符
+func syslogParamName(s string) string {
+	var b = make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < 32; i++ {
+		var c = s[i]
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+func (this *SyslogWriter) send(msg []byte) (err error) {
+	var n int
+	defer func() {
+		this.observeWrite(n, err)
+	}()
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	n, err = this.conn.write(this.connect, func(conn net.Conn) (int, error) {
+		return this.writeFrame(conn, msg)
+	})
+	return err
+}
+
+func (this *SyslogWriter) writeFrame(conn net.Conn, msg []byte) (int, error) {
+	if this.stream {
+		var frame = make([]byte, 0, len(msg)+8)
+		frame = strconv.AppendInt(frame, int64(len(msg)), 10)
+		frame = append(frame, ' ')
+		frame = append(frame, msg...)
+		return conn.Write(frame)
+	}
+	return conn.Write(msg)
+}
+
+func (this *SyslogWriter) connect() (net.Conn, error) {
+	if this.network != "" {
+		conn, err := net.DialTimeout(this.network, this.addr, kSyslogDialTimeout)
+		if err != nil {
+			return nil, err
+		}
+		this.stream = isStreamNetwork(this.network)
+		return conn, nil
+	}
+
+	var paths = kSyslogPaths
+	if this.addr != "" {
+		paths = []string{this.addr}
+	}
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.DialTimeout(network, path, kSyslogDialTimeout); err == nil {
+				this.stream = network == "unix"
+				return conn, nil
+			}
+		}
+	}
+	return nil, errors.New("无法连接本机的 syslog 服务")
+}
@@ -0,0 +1,162 @@
+package log4go_test
+
+import (
+	"bufio"
+	"github.com/smartwalle/log4go"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var sw = log4go.NewSyslogWriter(log4go.LevelTrace, "udp", conn.LocalAddr().String(),
+		log4go.WithFacility(log4go.FacilityLocal0), log4go.WithAppName("app"), log4go.WithHostname("host"))
+	defer sw.Close()
+
+	var l = log4go.New()
+	l.AddWriter("syslog", sw)
+	l.GetLogger("db").Warnln("slow query", log4go.Any("sql", `select "a"`), log4go.Any("cost", 10))
+
+	var buf = make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// local0 * 8 + warning(4) = 132
+	var re = regexp.MustCompile(`^<132>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ host app \d+ db \[fields@32473 sql="select \\"a\\"" cost="10"\] slow query$`)
+	if msg := string(buf[:n]); !re.MatchString(msg) {
+		t.Fatalf("syslog 消息格式错误: %q", msg)
+	}
+}
+
+func readSyslogFrame(r *bufio.Reader) (string, error) {
+	size, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(size))
+	if err != nil {
+		return "", err
+	}
+	var msg = make([]byte, n)
+	if _, err = io.ReadFull(r, msg); err != nil {
+		return "", err
+	}
+	return string(msg), nil
+}
+
+func TestSyslogWriter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var frames = make(chan string, 16)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			// 每个连接只读取一条消息后断开，用于测试重新连接
+			msg, err := readSyslogFrame(bufio.NewReader(conn))
+			conn.Close()
+			if err == nil {
+				frames <- msg
+			}
+		}
+	}()
+
+	var sw = log4go.NewSyslogWriter(log4go.LevelTrace, "tcp", ln.Addr().String(),
+		log4go.WithSyslogFormat(log4go.SyslogRFC3164), log4go.WithAppName("app"), log4go.WithHostname("host"))
+	defer sw.Close()
+
+	var l = log4go.New()
+	l.AddWriter("syslog", sw)
+	l.Infoln("first", log4go.Any("id", 1))
+
+	select {
+	case msg := <-frames:
+		var re = regexp.MustCompile(`^<14>\w{3} [ \d]\d \d\d:\d\d:\d\d host app\[\d+\]: first id=1$`)
+		if !re.MatchString(msg) {
+			t.Fatalf("syslog 消息格式错误: %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有收到 syslog 消息")
+	}
+
+	// 服务端断开连接之后，写入失败时会重新连接
+	for i := 0; i < 50; i++ {
+		l.WriteMessage(1, log4go.LevelError, "second\n")
+		select {
+		case msg := <-frames:
+			if !strings.HasPrefix(msg, "<11>") || !strings.HasSuffix(msg, "app["+strconv.Itoa(os.Getpid())+"]: second") {
+				t.Fatalf("syslog 消息格式错误: %q", msg)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	t.Fatal("没有重新连接 syslog 服务")
+}
+
+func TestSyslogWriter_Backoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addr = ln.Addr().String()
+	ln.Close()
+
+	var sw = log4go.NewSyslogWriter(log4go.LevelTrace, "tcp", addr, log4go.WithSyslogBackoff(200*time.Millisecond, time.Second))
+	defer sw.Close()
+
+	if _, err = sw.Write([]byte("hello")); err == nil {
+		t.Fatal("连接失败时应该返回错误")
+	}
+
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	// 等待时间内不再尝试连接
+	if _, err = sw.Write([]byte("hello")); err == nil {
+		t.Fatal("等待时间内应该直接返回错误")
+	}
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(50 * time.Millisecond))
+	if conn, err := ln.Accept(); err == nil {
+		conn.Close()
+		t.Fatal("等待时间内不应该重新连接")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if _, err = sw.Write([]byte("hello")); err != nil {
+		t.Fatalf("等待时间之后应该重新连接: %v", err)
+	}
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	msg, err := readSyslogFrame(bufio.NewReader(conn))
+	if err != nil || !strings.HasSuffix(msg, " hello") {
+		t.Fatalf("syslog 消息错误: %q %v", msg, err)
+	}
+}
@@ -0,0 +1,131 @@
+package log4go
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	kWatchInterval = 5 * time.Second
+)
+
+// ConfigWatcher 定时检查配置文件，文件内容发生变化时重新应用到 Logger
+type ConfigWatcher struct {
+	mu       sync.Mutex
+	logger   Logger
+	path     string
+	interval time.Duration
+	onError  func(err error)
+	modTime  time.Time
+	size     int64
+	data     []byte
+	config   *Config
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// WatchConfig 加载配置文件并应用到 l，之后每隔 interval 检查一次文件是否有变化，
+// l 为 nil 时使用 SharedLogger。重新加载失败时保留当前配置，将错误交给 onError 处理，并在下次检查时重试
+func WatchConfig(l Logger, path string, interval time.Duration, onError func(err error)) (*ConfigWatcher, error) {
+	if l == nil {
+		l = sharedLogger
+	}
+	if interval <= 0 {
+		interval = kWatchInterval
+	}
+
+	var w = &ConfigWatcher{}
+	w.logger = l
+	w.path = path
+	w.interval = interval
+	w.onError = onError
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	if _, err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (this *ConfigWatcher) run() {
+	defer close(this.done)
+
+	var ticker = time.NewTicker(this.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-this.stop:
+			return
+		case <-ticker.C:
+			if _, err := this.Reload(); err != nil && this.onError != nil {
+				this.onError(err)
+			}
+		}
+	}
+}
+
+// Reload 检查配置文件，文件有变化时重新应用，返回值表示是否重新应用了配置
+func (this *ConfigWatcher) Reload() (bool, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	info, err := os.Stat(this.path)
+	if err != nil {
+		return false, err
+	}
+	if this.config != nil && info.ModTime().Equal(this.modTime) && info.Size() == this.size {
+		return false, nil
+	}
+
+	data, err := ioutil.ReadFile(this.path)
+	if err != nil {
+		return false, err
+	}
+	// 只更新了修改时间
+	if this.config != nil && bytes.Equal(data, this.data) {
+		this.modTime = info.ModTime()
+		this.size = info.Size()
+		return false, nil
+	}
+
+	// 成功应用之后才记录文件的信息，解析或者应用失败时（如创建日志目录失败）下次检查时会重试
+	format, err := configFormat(this.path)
+	if err != nil {
+		return false, err
+	}
+	cfg, err := DecodeConfig(bytes.NewReader(data), format)
+	if err != nil {
+		return false, err
+	}
+	if err = cfg.apply(this.logger, this.config); err != nil {
+		return false, err
+	}
+	this.config = cfg
+	this.modTime = info.ModTime()
+	this.size = info.Size()
+	this.data = data
+	return true, nil
+}
+
+// Config 返回当前生效的配置
+func (this *ConfigWatcher) Config() *Config {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.config
+}
+
+// Stop 停止检查配置文件，已经应用的配置保持不变
+func (this *ConfigWatcher) Stop() {
+	this.stopOnce.Do(func() {
+		close(this.stop)
+	})
+	<-this.done
+}
@@ -0,0 +1,168 @@
+package log4go_test
+
+import (
+	"github.com/smartwalle/log4go"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "log4go.json")
+	if err = ioutil.WriteFile(path, []byte(`{"level": "info", "writers": [{"name": "w1", "type": "memory"}, {"name": "w2", "type": "memory"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var l = log4go.New()
+	watcher, err := log4go.WatchConfig(l, path, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	var w1, w2 = configWriters["w1"], configWriters["w2"]
+	if l.Level() != log4go.LevelInfo || l.Writer("w1") != w1 || l.Writer("w2") != w2 {
+		t.Fatal("加载配置文件失败")
+	}
+
+	if changed, err := watcher.Reload(); changed || err != nil {
+		t.Fatalf("配置文件未发生变化时不应该重新加载: %v %v", changed, err)
+	}
+
+	if err = ioutil.WriteFile(path, []byte(`{"writers": [{"name": "w1", "type": "memory"}, {"name": "w3", "type": "memory", "level": "error"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 避免文件系统的时间精度导致修改时间相同
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+
+	if changed, err := watcher.Reload(); !changed || err != nil {
+		t.Fatalf("重新加载配置文件失败: %v %v", changed, err)
+	}
+
+	if l.Level() != log4go.LevelTrace {
+		t.Fatalf("配置中移除的级别应该恢复为默认值, 实际为 %v", l.Level())
+	}
+	if l.Writer("w1") != w1 || w1.Closed() {
+		t.Fatal("配置未发生变化的 Writer 应该被保留")
+	}
+	if l.Writer("w2") != nil || !w2.Closed() {
+		t.Fatal("配置中移除的 Writer 应该被关闭")
+	}
+	if w3, ok := l.Writer("w3").(*memoryWriter); !ok || w3.Level() != log4go.LevelError {
+		t.Fatal("配置中新增的 Writer 未生效")
+	}
+
+	if err = ioutil.WriteFile(path, []byte(`{"level": "verbose"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second))
+	if _, err = watcher.Reload(); err == nil {
+		t.Fatal("错误的配置文件应该返回错误")
+	}
+	if l.Writer("w1") != w1 {
+		t.Fatal("加载失败时应该保留当前配置")
+	}
+
+	// 编辑器保存文件时可能先将其清空
+	for i, data := range []string{"", "  \n", "{}"} {
+		if err = ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, time.Now(), time.Now().Add(time.Duration(3+i)*time.Second))
+		if changed, err := watcher.Reload(); changed || err == nil {
+			t.Fatalf("空的配置文件 %q 应该返回错误: %v %v", data, changed, err)
+		}
+		if l.Writer("w1") != w1 || w1.Closed() {
+			t.Fatal("空的配置文件不应该移除 Writer")
+		}
+	}
+}
+
+func TestWatchConfig_RetryAfterApplyError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "log4go.json")
+	if err = ioutil.WriteFile(path, []byte(`{"writers": [{"name": "w1", "type": "memory"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var l = log4go.New()
+	watcher, err := log4go.WatchConfig(l, path, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	// logs 为普通文件，创建日志目录失败
+	var logs = filepath.Join(dir, "logs")
+	if err = ioutil.WriteFile(logs, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var data = `{"writers": [{"name": "file", "type": "file", "dir": "` + filepath.ToSlash(filepath.Join(logs, "app")) + `"}]}`
+	if err = ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+
+	for i := 0; i < 2; i++ {
+		if changed, err := watcher.Reload(); changed || err == nil {
+			t.Fatalf("应用配置失败时应该返回错误: %v %v", changed, err)
+		}
+	}
+
+	// 文件未发生变化，问题解决之后再次检查时应该重试
+	os.Remove(logs)
+	if changed, err := watcher.Reload(); !changed || err != nil {
+		t.Fatalf("应用配置失败之后应该重试: %v %v", changed, err)
+	}
+	if _, ok := l.Writer("file").(*log4go.FileWriter); !ok {
+		t.Fatal("配置中新增的 Writer 未生效")
+	}
+	if changed, err := watcher.Reload(); changed || err != nil {
+		t.Fatalf("配置文件未发生变化时不应该重新加载: %v %v", changed, err)
+	}
+}
+
+func TestDecodeConfig_Empty(t *testing.T) {
+	var tests = []struct {
+		format string
+		data   string
+	}{
+		{log4go.ConfigFormatYAML, ""},
+		{log4go.ConfigFormatYAML, "# comment\n"},
+		{log4go.ConfigFormatYAML, "~"},
+		{log4go.ConfigFormatJSON, ""},
+		{log4go.ConfigFormatJSON, "{}"},
+	}
+	for _, test := range tests {
+		if _, err := log4go.DecodeConfig(strings.NewReader(test.data), test.format); err == nil {
+			t.Fatalf("空的 %s 配置信息 %q 应该返回错误", test.format, test.data)
+		}
+	}
+
+	var dir, err = ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var path = filepath.Join(dir, "log4go.yaml")
+	if err = ioutil.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = log4go.LoadConfig(path); err == nil {
+		t.Fatal("空的配置文件应该返回错误")
+	}
+}